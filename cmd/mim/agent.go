@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jonsampson/mim/internal/config"
+	"github.com/jonsampson/mim/internal/remote"
+	"github.com/jonsampson/mim/internal/tui"
+)
+
+// defaultAgentAddress is where "mim agent" listens unless told otherwise.
+// The agent has no authentication, so other machines only reach it when
+// -listen names a public address.
+const defaultAgentAddress = "127.0.0.1:7070"
+
+// runAgent implements "mim agent": run the enabled collectors and stream
+// their metrics to TUIs started with -connect
+func runAgent(args []string) {
+	flags := flag.NewFlagSet("agent", flag.ExitOnError)
+	listen := flags.String("listen", defaultAgentAddress, "accept clients on `address` (host:port or unix:/path)")
+	cgroup := flags.String("cgroup", "", "scope pressure metrics to a cgroup v2 `path` (relative to /sys/fs/cgroup)")
+	configPath := flags.String("config", config.DefaultPath(), "read configuration from `file`")
+	enable := flags.String("enable", "", "comma-separated `collectors` to enable")
+	disable := flags.String("disable", "", "comma-separated `collectors` to disable")
+	record := flags.String("record", "", "also write the metrics to `file`, which -connect can replay")
+	flags.Parse(args)
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	registry := tui.NewRegistry()
+	if err := registerBuiltinCollectors(registry, collectorOptions{cgroup: *cgroup}); err != nil {
+		fmt.Printf("Error registering collectors: %v\n", err)
+		os.Exit(1)
+	}
+	plugins, err := selectPlugins(registry, cfg, *enable, *disable)
+	if err != nil {
+		fmt.Printf("Error selecting collectors: %v\n", err)
+		os.Exit(1)
+	}
+
+	agent := remote.NewAgent()
+	for _, plugin := range plugins {
+		agent.Publish(plugin.Name, tui.Stream(plugin.NewSource()))
+	}
+
+	if *record != "" {
+		file, err := os.Create(*record)
+		if err != nil {
+			fmt.Printf("Error creating recording: %v\n", err)
+			os.Exit(1)
+		}
+		go func() {
+			if err := agent.Record(file); err != nil {
+				log.Printf("recording stopped: %v", err)
+			}
+		}()
+	}
+
+	listener, err := remote.Listen(*listen)
+	if err != nil {
+		fmt.Printf("Error listening on %s: %v\n", *listen, err)
+		os.Exit(1)
+	}
+	log.Printf("mim agent publishing %v on %s", agent.Streams(), *listen)
+	if err := agent.Serve(listener); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/jonsampson/mim/internal/config"
+	"github.com/jonsampson/mim/internal/remote"
+	"github.com/jonsampson/mim/internal/tui"
+)
+
+// clusterSummaryCollectors feed the rows of the cluster overview; the other
+// collectors of a host run once it is opened
+var clusterSummaryCollectors = []string{"cpu", "nvidia"}
+
+// newCluster builds the cluster overview for several agents or recordings.
+// Hosts that cannot be reached yet are listed and picked up when they come
+// up.
+func newCluster(addresses []string, cfg config.Config, opts collectorOptions, enable, disable string, configure func(*tui.Model)) (tui.ClusterModel, error) {
+	var hosts []tui.ClusterHost
+	names := make(map[string]bool)
+	for _, address := range addresses {
+		hostOpts := opts
+		hostOpts.connect = address
+		info, err := remote.Probe(address)
+		if err != nil {
+			log.Printf("cluster: %s: %v", address, err)
+			info = remote.Info{Hostname: address}
+		}
+		hostOpts.agent = info
+
+		registry := tui.NewRegistry()
+		if err := registerBuiltinCollectors(registry, hostOpts); err != nil {
+			return tui.ClusterModel{}, err
+		}
+		plugins, err := selectPlugins(registry, cfg, enable, disable)
+		if err != nil {
+			return tui.ClusterModel{}, err
+		}
+
+		// A recording of a host may be shown next to the host itself
+		name := info.Hostname
+		if name == "" {
+			name = address
+		} else if names[name] {
+			name = fmt.Sprintf("%s (%s)", name, address)
+		}
+		names[name] = true
+
+		host := tui.ClusterHost{Name: name, Plugins: plugins}
+		for _, plugin := range plugins {
+			if slices.Contains(clusterSummaryCollectors, plugin.Name) {
+				host.Summary = append(host.Summary, plugin)
+			}
+		}
+		hosts = append(hosts, host)
+	}
+	return tui.NewClusterModel(hosts, configure)
+}
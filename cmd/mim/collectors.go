@@ -0,0 +1,91 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/jonsampson/mim/internal/infra"
+	"github.com/jonsampson/mim/internal/remote"
+	"github.com/jonsampson/mim/internal/tui"
+)
+
+// collectorOptions holds flag values that built-in collectors depend on
+type collectorOptions struct {
+	cgroup string
+	// connect is the agent address or recording when attached with
+	// -connect; agent lists the streams it publishes
+	connect string
+	agent   remote.Info
+}
+
+// source constructs a collector, or when attached to an agent a remote
+// collector for the same stream
+func source[T any, C infra.MetricsCollector[T]](opts collectorOptions, name string, newCollector func() C) func() tui.Source {
+	return func() tui.Source {
+		if opts.connect != "" {
+			return tui.NewSource[T](remote.NewCollector[T](opts.connect, name))
+		}
+		return tui.NewSource[T](newCollector())
+	}
+}
+
+// registerBuiltinCollectors registers the collectors shipped with mim.
+// Additional collectors register the same way.
+func registerBuiltinCollectors(registry *tui.Registry, opts collectorOptions) error {
+	plugins := []tui.Plugin{
+		{
+			Name:           "cpu",
+			Description:    "CPU, memory and process metrics",
+			DefaultEnabled: true,
+			NewSource:      source[domain.CPUMemoryMetrics](opts, "cpu", infra.NewCPUMemoryCollector),
+		},
+		{
+			Name:           "nvidia",
+			Description:    "NVIDIA GPU utilization, memory and processes via NVML",
+			Available:      infra.HasNvidiaGPU,
+			DefaultEnabled: true,
+			NewSource:      source[domain.GPUMetrics](opts, "nvidia", infra.NewNvidiaGPUCollector),
+		},
+		// TODO: Register an AMD GPU collector once detection is implemented
+		{
+			Name:           "psi",
+			Description:    "Linux pressure stall information, optionally for a cgroup",
+			DefaultEnabled: true,
+			NewSource: source[domain.PSIMetrics](opts, "psi", func() *infra.PSICollector {
+				return infra.NewPSICollector(opts.cgroup)
+			}),
+			NewPanel: func() tui.Panel { return tui.NewPSIPanel() },
+		},
+		{
+			Name:           "activity",
+			Description:    "Load average, context switches, interrupts and run queue",
+			Available:      func() bool { return infra.HasProcFile("loadavg") },
+			DefaultEnabled: true,
+			NewSource:      source[domain.SystemActivityMetrics](opts, "activity", infra.NewSystemActivityCollector),
+			NewPanel:       func() tui.Panel { return tui.NewSystemActivityHeader() },
+		},
+		{
+			Name:           "disknet",
+			Description:    "Block device and network interface throughput",
+			Available:      func() bool { return infra.HasProcFile("diskstats") },
+			DefaultEnabled: true,
+			NewSource:      source[domain.DiskNetMetrics](opts, "disknet", infra.NewDiskNetCollector),
+			NewPanel:       func() tui.Panel { return tui.NewDiskNetPanel() },
+		},
+	}
+
+	for _, plugin := range plugins {
+		if opts.connect != "" {
+			// What can run is decided by what the agent publishes. An agent
+			// that could not be reached is assumed to publish everything.
+			name := plugin.Name
+			plugin.Available = func() bool {
+				return opts.agent.Streams == nil || slices.Contains(opts.agent.Streams, name)
+			}
+		}
+		if err := registry.Register(plugin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
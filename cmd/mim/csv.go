@@ -0,0 +1,72 @@
+package main
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/jonsampson/mim/internal/export"
+	"github.com/jonsampson/mim/internal/tui"
+)
+
+// csvSchemaWait bounds how long rows are held back waiting for every
+// collector to report
+const csvSchemaWait = 3 * time.Second
+
+// runCSV writes a system row to w for every CPU sample, and process rows to
+// processPath when set. Rows start once every collector has reported, so
+// the GPU columns are part of the schema. It returns when the collectors
+// stop or writing fails.
+func runCSV(w io.Writer, processPath string, plugins []tui.Plugin) error {
+	var processes io.Writer
+	if processPath != "" {
+		file, err := os.Create(processPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		processes = file
+	}
+	writer := export.NewCSVWriter(w, processes)
+
+	type sample struct {
+		source string
+		value  any
+	}
+	samples := make(chan sample)
+	var streams sync.WaitGroup
+	for _, plugin := range plugins {
+		values := tui.Stream(plugin.NewSource())
+		streams.Add(1)
+		go func() {
+			defer streams.Done()
+			for value := range values {
+				samples <- sample{source: plugin.Name, value: value}
+			}
+		}()
+	}
+	// A finite source such as a recording ends the run once it is drained
+	go func() {
+		streams.Wait()
+		close(samples)
+	}()
+
+	seen := make(map[string]bool)
+	deadline := time.Now().Add(csvSchemaWait)
+	for s := range samples {
+		seen[s.source] = true
+		writer.Update(s.value)
+		if _, ok := s.value.(domain.CPUMemoryMetrics); !ok {
+			continue
+		}
+		if len(seen) < len(plugins) && time.Now().Before(deadline) {
+			continue
+		}
+		if err := writer.WriteRow(time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/jonsampson/mim/internal/export"
+	"github.com/jonsampson/mim/internal/tui"
+)
+
+// exportOptions holds the flags that configure metric exporters
+type exportOptions struct {
+	otlpEndpoint string
+	otlpProtocol string
+	influx       string
+	statsd       string
+	dogstatsd    bool
+}
+
+// sinks constructs the configured exporters; none when no endpoint is set
+func (o exportOptions) sinks() ([]export.Sink, error) {
+	var sinks []export.Sink
+	if o.otlpEndpoint != "" {
+		sink, err := export.NewOTLPSink(o.otlpProtocol, o.otlpEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if o.influx != "" {
+		sink, err := export.NewInfluxSink(o.influx)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if o.statsd != "" {
+		sink, err := export.NewStatsDSink(o.statsd, o.dogstatsd)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// startExporters runs the exporters in the background. The returned func
+// stops them and waits until they have closed their sinks; it may be called
+// more than once.
+func startExporters(exporters []*export.Exporter) func() {
+	stop := make(chan struct{})
+	var running sync.WaitGroup
+	for _, exporter := range exporters {
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			exporter.Run(stop)
+		}()
+	}
+	return sync.OnceFunc(func() {
+		close(stop)
+		running.Wait()
+	})
+}
+
+// runHeadless feeds the plugins' metrics to the exporters, which are already
+// running, without a UI. It does not return; the process ends on a signal.
+func runHeadless(plugins []tui.Plugin, exporters []*export.Exporter) {
+	for _, plugin := range plugins {
+		values := tui.Stream(plugin.NewSource())
+		go func() {
+			for value := range values {
+				for _, exporter := range exporters {
+					exporter.Update(value)
+				}
+			}
+		}()
+	}
+	select {}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jonsampson/mim/internal/config"
+	"github.com/jonsampson/mim/internal/tui"
+)
+
+// buildLayout resolves the layout from the config file and flags. A preset
+// flag wins over the config file; hidden panels from both are combined.
+// Naming a panel missing from panels is an error.
+func buildLayout(cfg *config.LayoutConfig, preset string, hidden []string, panels []string) (tui.Layout, error) {
+	if cfg == nil {
+		cfg = &config.LayoutConfig{}
+	}
+	hidden = append(hidden, cfg.Hidden...)
+	if err := tui.CheckPanelNames(hidden, panels); err != nil {
+		return tui.Layout{}, fmt.Errorf("hiding panels: %w", err)
+	}
+
+	if preset == "" && len(cfg.Rows) > 0 {
+		layout := layoutFromConfig(cfg.Rows)
+		if err := tui.CheckPanelNames(layout.Panels(), panels); err != nil {
+			return tui.Layout{}, err
+		}
+		return layout.Hide(hidden...), nil
+	}
+	if preset == "" {
+		preset = cfg.Preset
+	}
+	if preset == "" {
+		preset = "default"
+	}
+
+	layout, err := tui.LayoutPreset(preset)
+	if err != nil {
+		return tui.Layout{}, err
+	}
+	return layout.Hide(hidden...), nil
+}
+
+func layoutFromConfig(rows []config.LayoutRow) tui.Layout {
+	var layout tui.Layout
+	for _, row := range rows {
+		layoutRow := tui.LayoutRow{Lines: row.Lines, Weight: row.Weight}
+		for _, panel := range row.Panels {
+			layoutRow.Panels = append(layoutRow.Panels, tui.LayoutPanel{
+				Name:   panel.Name,
+				Weight: panel.Weight,
+				Hidden: panel.Hidden,
+			})
+		}
+		layout.Rows = append(layout.Rows, layoutRow)
+	}
+	return layout
+}
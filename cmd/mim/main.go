@@ -9,18 +9,194 @@ import (
 	"os"
 	"os/signal"
 	"runtime/pprof"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/jonsampson/mim/internal/infra"
+	"github.com/jonsampson/mim/internal/config"
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/jonsampson/mim/internal/export"
+	"github.com/jonsampson/mim/internal/remote"
+	"github.com/jonsampson/mim/internal/store"
 	"github.com/jonsampson/mim/internal/tui"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "agent" {
+		runAgent(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "query" {
+		runQuery(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "report" {
+		runReport(os.Args[2:])
+		return
+	}
+
+	// mim run takes mim's flags before "--" and the command after
+	args := os.Args[1:]
+	var command []string
+	if len(os.Args) > 1 && os.Args[1] == "run" {
+		var err error
+		args, command, err = splitRunArgs(os.Args[2:])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
 	// Parse command line flags
 	var cpuprofile = flag.String("cpuprofile", "", "write cpu profile to `file`")
 	var webpprof = flag.Bool("webpprof", false, "enable web-based pprof on :6060")
-	flag.Parse()
+	var cgroup = flag.String("cgroup", "", "scope pressure metrics to a cgroup v2 `path` (relative to /sys/fs/cgroup)")
+	var configPath = flag.String("config", config.DefaultPath(), "read configuration from `file`")
+	var enable = flag.String("enable", "", "comma-separated `collectors` to enable")
+	var disable = flag.String("disable", "", "comma-separated `collectors` to disable")
+	var listCollectors = flag.Bool("list-collectors", false, "list available collectors and exit")
+	var layoutPreset = flag.String("layout", "", "use the layout `preset` (default, gpu-box, cpu-server, laptop)")
+	var hide = flag.String("hide", "", "comma-separated `panels` to hide")
+	var compact = flag.Bool("compact", false, "always use the compact view (used automatically in small terminals)")
+	var oneline = flag.Bool("oneline", false, "print a one-line summary every second, for tmux status-right")
+	var connect = flag.String("connect", "", "show metrics from the agent or recording at `address` (host:port, unix:/path or a file); several comma-separated addresses show a cluster overview")
+	var otlpEndpoint = flag.String("otlp-endpoint", "", "push metrics to the OTLP receiver at `address` (a URL for http, host:port for grpc)")
+	var otlpProtocol = flag.String("otlp-protocol", export.OTLPHTTP, "OTLP transport: http or grpc")
+	var influx = flag.String("influx", "", "write metrics as InfluxDB line protocol to `target` (a file, - for stdout, or udp://host:port)")
+	var statsd = flag.String("statsd", "", "send metrics as StatsD gauges to `host:port` over UDP")
+	var dogstatsd = flag.Bool("dogstatsd", false, "send StatsD gauges with DogStatsD tags")
+	var exportInterval = flag.Duration("export-interval", export.DefaultInterval, "push metrics to exporters every `duration`")
+	var output = flag.String("output", "", "write metrics to stdout instead of running the TUI; `format` is csv")
+	var processCSV = flag.String("process-csv", "", "with -output csv, also write per-process rows to `file`")
+	var headless = flag.Bool("headless", false, "run only the collectors and exporters, without the TUI")
+	var history = flag.Duration("history", tui.DefaultHistoryRetention, "keep `duration` of metrics to step through while paused")
+	var storeMetrics = flag.Bool("store", false, "record metrics to the on-disk store for \"mim query\" and -load-from")
+	var storeDir = flag.String("store-dir", store.DefaultDir(), "keep the metrics store in `dir`")
+	var loadFrom = flag.String("load-from", "", "start paused on stored metrics from `time` (a time or how long ago, like 2h)")
+	var loadTo = flag.String("load-to", "now", "with -load-from, show stored metrics up to `time`")
+	var summary = flag.Bool("summary", false, "print min, average, p95 and max of every metric and the busiest processes on exit")
+	var jobLog = flag.String("job-log", "mim-run.log", "with mim run, append the command's output to `file`")
+	var pids = flag.String("pid", "", "always show the comma-separated `pids` in a Watched table")
+	var pidFile = flag.String("pid-file", "", "watch the PIDs listed in `file`")
+	var pgrep = flag.String("pgrep", "", "watch processes whose command matches the `regexp`")
+	flag.CommandLine.Parse(args)
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts := collectorOptions{cgroup: *cgroup}
+	addresses := splitList(*connect)
+	if len(addresses) == 1 {
+		opts.connect = addresses[0]
+		opts.agent, err = remote.Probe(opts.connect)
+		if err != nil {
+			fmt.Printf("Error connecting to agent: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if len(addresses) > 1 && *oneline {
+		fmt.Println("-oneline takes a single -connect address")
+		os.Exit(1)
+	}
+
+	exportOpts := exportOptions{
+		otlpEndpoint: *otlpEndpoint,
+		otlpProtocol: *otlpProtocol,
+		influx:       *influx,
+		statsd:       *statsd,
+		dogstatsd:    *dogstatsd,
+	}
+	if *influx == "-" && !*headless {
+		fmt.Println("-influx - writes to stdout and needs -headless")
+		os.Exit(1)
+	}
+	sinks, err := exportOpts.sinks()
+	if err != nil {
+		fmt.Printf("Error configuring export: %v\n", err)
+		os.Exit(1)
+	}
+	if (len(sinks) > 0 || *storeMetrics) && len(addresses) > 1 {
+		fmt.Println("Exporting and -store take a single -connect address")
+		os.Exit(1)
+	}
+	if *output != "" && *output != "csv" {
+		fmt.Printf("Unknown output format %q\n", *output)
+		os.Exit(1)
+	}
+	if *processCSV != "" && *output != "csv" {
+		fmt.Println("-process-csv needs -output csv")
+		os.Exit(1)
+	}
+	if *oneline && (len(sinks) > 0 || *storeMetrics) {
+		fmt.Println("-oneline does not export; use -headless")
+		os.Exit(1)
+	}
+	if *headless && len(sinks) == 0 && !*storeMetrics {
+		fmt.Println("-headless needs an exporter: -otlp-endpoint, -influx, -statsd or -store")
+		os.Exit(1)
+	}
+	if *loadFrom != "" && (*headless || *oneline || *output != "" || len(addresses) > 1) {
+		fmt.Println("-load-from needs the single-host TUI")
+		os.Exit(1)
+	}
+	if command != nil && (*headless || *oneline || *output != "" || *loadFrom != "" || len(addresses) > 0) {
+		fmt.Println("mim run needs the local TUI")
+		os.Exit(1)
+	}
+	watch, err := watchList(*pids, *pidFile, *pgrep)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if watch != nil && (command != nil || *headless || *oneline || *output != "" || len(addresses) > 1) {
+		fmt.Println("-pid, -pid-file and -pgrep need the single-host TUI, without mim run")
+		os.Exit(1)
+	}
+	if *summary && (*headless || *oneline || *output != "" || len(addresses) > 1) {
+		fmt.Println("-summary needs the single-host TUI")
+		os.Exit(1)
+	}
+
+	registry := tui.NewRegistry()
+	if err := registerBuiltinCollectors(registry, opts); err != nil {
+		fmt.Printf("Error registering collectors: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *listCollectors {
+		printCollectors(registry)
+		return
+	}
+
+	plugins, err := selectPlugins(registry, cfg, *enable, *disable)
+	if err != nil {
+		fmt.Printf("Error selecting collectors: %v\n", err)
+		os.Exit(1)
+	}
+
+	layout, err := buildLayout(cfg.Layout, *layoutPreset, splitList(*hide), tui.PanelNames(registry.Plugins()))
+	if err != nil {
+		fmt.Printf("Error loading layout: %v\n", err)
+		os.Exit(1)
+	}
+
+	keys := tui.DefaultKeyMap()
+	if err := keys.Remap(cfg.Keys); err != nil {
+		fmt.Printf("Error loading key bindings: %v\n", err)
+		os.Exit(1)
+	}
+	if conflicts := keys.Conflicts(); len(conflicts) > 0 {
+		fmt.Println("Conflicting key bindings:")
+		for _, conflict := range conflicts {
+			fmt.Printf("  %s\n", conflict)
+		}
+		os.Exit(1)
+	}
 
 	// Start web-based pprof if requested
 	if *webpprof {
@@ -43,55 +219,220 @@ func main() {
 			log.Fatal("could not start CPU profile: ", err)
 		}
 		log.Printf("CPU profiling started, writing to %s", *cpuprofile)
-		
-		// Ensure profiling is stopped on exit
-		defer func() {
-			pprof.StopCPUProfile()
-			cpuProfileFile.Close()
-			log.Printf("CPU profile written to %s", *cpuprofile)
-		}()
 	}
 
-	// Setup signal handler for clean shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		if cpuProfileFile != nil {
-			pprof.StopCPUProfile()
-			cpuProfileFile.Close()
-			log.Printf("CPU profile written to %s (interrupted)", *cpuprofile)
-		}
-		os.Exit(0)
-	}()
+	// finish writes the CPU profile and closes the debug log. os.Exit skips
+	// deferred calls, so it is called before exiting with a status.
+	var logFile *os.File
+	var finishOnce sync.Once
+	finish := func() {
+		finishOnce.Do(func() {
+			if cpuProfileFile != nil {
+				pprof.StopCPUProfile()
+				cpuProfileFile.Close()
+				log.Printf("CPU profile written to %s", *cpuprofile)
+			}
+			if logFile != nil {
+				logFile.Close()
+			}
+		})
+	}
+	defer finish()
 
 	// Open a log file for debugging
-	logFile, err := os.OpenFile("debug.log", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	logFile, err = os.OpenFile("debug.log", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
 	if err != nil {
 		fmt.Printf("Error opening log file: %v\n", err)
 		os.Exit(1)
 	}
-	defer logFile.Close()
 
 	// Set up the log package to write to the log file
 	log.SetOutput(logFile)
 
-	factory := infra.CollectorFactory{}
-	collectors := factory.CreateCollectors()
+	// Metrics are labelled with the host they were collected on
+	exportHost := opts.agent.Hostname
+	if exportHost == "" {
+		exportHost, _ = os.Hostname()
+	}
+	var exporters []*export.Exporter
+	if len(sinks) > 0 {
+		exporters = append(exporters, export.NewExporter(exportHost, *exportInterval, sinks...))
+	}
+	if *storeMetrics {
+		exporter, err := storeExporter(exportHost, *storeDir)
+		if err != nil {
+			fmt.Printf("Error opening store: %v\n", err)
+			os.Exit(1)
+		}
+		exporters = append(exporters, exporter)
+	}
+	stopExporters := startExporters(exporters)
+	defer stopExporters()
 
-	// Initialize the model without specifying the initial size
-	model, err := tui.InitialModel(collectors...)
-	if err != nil {
-		log.Printf("Error initializing model: %v\n", err)
-		fmt.Printf("Error initializing model: %v\n", err)
-		os.Exit(1)
+	// Setup signal handler for clean shutdown. With mim run, the signal
+	// stops the job like quitting does, so mim exits with the job's status.
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	if command == nil {
+		go func() {
+			<-sigChan
+			stopExporters()
+			finish()
+			os.Exit(0)
+		}()
+	}
+
+	if *headless {
+		runHeadless(plugins, exporters)
+	}
+
+	if *output == "csv" {
+		if err := runCSV(os.Stdout, *processCSV, plugins); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *oneline {
+		if err := tui.RunOneline(os.Stdout, nil, plugins...); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// configure applies the view settings to the host view, and in a
+	// cluster to each host as it is opened
+	configure := func(model *tui.Model) {
+		model.SetLayout(layout)
+		model.SetKeyMap(keys)
+		model.SetCompact(*compact)
+		model.SetHistoryRetention(*history)
+	}
+
+	var program tea.Model
+	var running *job // started by mim run
+	if len(addresses) > 1 {
+		cluster, err := newCluster(addresses, cfg, opts, *enable, *disable, configure)
+		if err != nil {
+			fmt.Printf("Error initializing cluster: %v\n", err)
+			os.Exit(1)
+		}
+		cluster.SetKeyMap(keys)
+		program = cluster
+	} else {
+		// Initialize the model without specifying the initial size
+		model, err := tui.InitialModel(plugins...)
+		if err != nil {
+			log.Printf("Error initializing model: %v\n", err)
+			fmt.Printf("Error initializing model: %v\n", err)
+			os.Exit(1)
+		}
+		configure(&model)
+		model.SetHost(opts.agent.Hostname)
+		if len(exporters) > 0 {
+			model.SetMetricsObserver(func(value any) {
+				for _, exporter := range exporters {
+					exporter.Update(value)
+				}
+			})
+		}
+		if watch != nil {
+			model.SetWatchList(watch)
+		}
+		if command != nil {
+			running, err = startJob(command, *jobLog)
+			if err != nil {
+				fmt.Printf("Error starting %s: %v\n", command[0], err)
+				os.Exit(1)
+			}
+			model.SetProcessTree(domain.NewProcessTree(running.pid()), running.command)
+		}
+		if *loadFrom != "" {
+			samples, tier, err := loadArchive(*storeDir, *loadFrom, *loadTo)
+			if err != nil {
+				fmt.Printf("Error loading stored metrics: %v\n", err)
+				os.Exit(1)
+			}
+			model.ShowArchive(samples, tier.Step)
+		}
+		program = model
 	}
 
 	// Initialize the Bubble Tea program
-	p := tea.NewProgram(model)
-	if _, err := p.Run(); err != nil {
+	// All-motion mouse reporting drives the graph tooltips
+	p := tea.NewProgram(program, tea.WithMouseAllMotion())
+	if running != nil {
+		// The TUI ends with the job, or when mim is signalled
+		go func() {
+			select {
+			case <-running.done:
+			case <-sigChan:
+			}
+			p.Quit()
+		}()
+	}
+	final, err := p.Run()
+	if err != nil {
 		p.Kill()
 		log.Printf("Alas, there's been an error: %v", err)
 		fmt.Printf("Alas, there's been an error: %v", err)
 	}
+	if running != nil {
+		running.stop()
+	}
+	// The sinks are flushed and closed before the summary is printed
+	stopExporters()
+	if model, ok := final.(tui.Model); ok && *summary && err == nil {
+		if running != nil {
+			fmt.Printf("%s: exit status %d after %s\n\n", running.command, running.exitCode(), time.Since(running.started).Round(time.Second))
+		}
+		fmt.Print(tui.FormatRunStats(model.RunStats(), summaryProcesses))
+	}
+	if running != nil {
+		code := running.exitCode()
+		finish()
+		os.Exit(code)
+	}
+}
+
+// summaryProcesses is how many processes -summary lists
+const summaryProcesses = 10
+
+func printCollectors(registry *tui.Registry) {
+	for _, plugin := range registry.Plugins() {
+		status := "available"
+		if !plugin.IsAvailable() {
+			status = "unavailable"
+		}
+		if !plugin.DefaultEnabled {
+			status += ", disabled by default"
+		}
+		fmt.Printf("%-10s %-32s %s\n", plugin.Name, "("+status+")", plugin.Description)
+	}
+}
+
+// selectPlugins resolves the collectors to run; flags override the config
+// file
+func selectPlugins(registry *tui.Registry, cfg config.Config, enable, disable string) ([]tui.Plugin, error) {
+	overrides := cfg.CollectorOverrides()
+	for _, name := range splitList(enable) {
+		overrides[name] = true
+	}
+	for _, name := range splitList(disable) {
+		overrides[name] = false
+	}
+	return registry.Resolve(overrides)
+}
+
+// splitList splits a comma-separated flag value, ignoring empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/jonsampson/mim/internal/remote"
+	"github.com/jonsampson/mim/internal/report"
+)
+
+// runReport implements "mim report": render a recording as a standalone
+// HTML page
+func runReport(args []string) {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	input := flags.String("input", "", "read the recording made with \"mim agent -record\" from `file`")
+	out := flags.String("out", "report.html", "write the report to `file`")
+	flags.Parse(args)
+	if *input == "" {
+		fmt.Println("-input is required")
+		os.Exit(1)
+	}
+
+	if err := writeReport(*input, *out); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// writeReport reads the CPU and GPU streams of a recording into a report
+func writeReport(input, out string) error {
+	info, err := remote.Probe(input)
+	if err != nil {
+		return err
+	}
+	r := report.New(info.Hostname)
+	_, err = remote.ReadRecording(input, func(at time.Time, stream string, data json.RawMessage) error {
+		switch stream {
+		case "cpu":
+			var metrics domain.CPUMemoryMetrics
+			if err := json.Unmarshal(data, &metrics); err != nil {
+				return fmt.Errorf("decoding cpu sample: %w", err)
+			}
+			r.Add(at, metrics)
+		case "nvidia":
+			var metrics domain.GPUMetrics
+			if err := json.Unmarshal(data, &metrics); err != nil {
+				return fmt.Errorf("decoding nvidia sample: %w", err)
+			}
+			r.Add(at, metrics)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := r.Write(file); err != nil {
+		file.Close()
+		os.Remove(out)
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// jobGrace is how long mim waits for an interrupted job before killing it
+const jobGrace = 10 * time.Second
+
+// job is the command started by mim run
+type job struct {
+	cmd     *exec.Cmd
+	command string
+	started time.Time
+	done    chan struct{} // closed when the command has exited
+}
+
+// splitRunArgs splits the arguments of mim run at "--" into mim's flags and
+// the command
+func splitRunArgs(args []string) (flags, command []string, err error) {
+	i := slices.Index(args, "--")
+	if i < 0 || i == len(args)-1 {
+		return nil, nil, errors.New("usage: mim run [flags] -- command [args...]")
+	}
+	return args[:i], args[i+1:], nil
+}
+
+// startJob starts command with its output appended to logPath, since the
+// TUI owns the terminal, and no input. The command leads a process group of
+// its own, so stopping it reaches every process it started.
+func startJob(command []string, logPath string) (*job, error) {
+	logFile, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		return nil, err
+	}
+	j := &job{cmd: cmd, command: strings.Join(command, " "), started: time.Now(), done: make(chan struct{})}
+	go func() {
+		defer close(j.done)
+		defer logFile.Close()
+		cmd.Wait()
+	}()
+	return j, nil
+}
+
+func (j *job) pid() uint32 {
+	return uint32(j.cmd.Process.Pid)
+}
+
+// stop interrupts the command's process group, as Ctrl+C in a terminal
+// would, and kills the group if the command has not exited after jobGrace
+func (j *job) stop() {
+	select {
+	case <-j.done:
+		return
+	default:
+	}
+	fmt.Printf("Waiting for %s to exit\n", j.command)
+	group := -j.cmd.Process.Pid
+	syscall.Kill(group, syscall.SIGINT)
+	select {
+	case <-j.done:
+	case <-time.After(jobGrace):
+		syscall.Kill(group, syscall.SIGKILL)
+		<-j.done
+	}
+}
+
+// exitCode waits for the command and returns its exit status; like shells,
+// 128 plus the signal number when a signal ended it
+func (j *job) exitCode() int {
+	<-j.done
+	state := j.cmd.ProcessState
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return state.ExitCode()
+}
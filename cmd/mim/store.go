@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jonsampson/mim/internal/export"
+	"github.com/jonsampson/mim/internal/store"
+)
+
+// storeExporter writes a sample to the store in dir every second
+func storeExporter(host, dir string) (*export.Exporter, error) {
+	s, err := store.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	return export.NewExporter(host, time.Second, s), nil
+}
+
+// loadArchive reads the stored samples between the -load-from and -load-to
+// times
+func loadArchive(dir, from, to string) ([]store.Sample, store.Tier, error) {
+	start, end, err := parseRange(from, to, time.Now())
+	if err != nil {
+		return nil, store.Tier{}, err
+	}
+	samples, tier, err := store.Query(dir, start, end)
+	if err != nil {
+		return nil, store.Tier{}, err
+	}
+	if len(samples) == 0 {
+		return nil, store.Tier{}, fmt.Errorf("no stored metrics between %s and %s", start.Format(time.DateTime), end.Format(time.DateTime))
+	}
+	return samples, tier, nil
+}
+
+// runQuery implements "mim query": print statistics of the stored metrics
+// for a time range
+func runQuery(args []string) {
+	flags := flag.NewFlagSet("query", flag.ExitOnError)
+	dir := flags.String("store-dir", store.DefaultDir(), "read the metrics store in `dir`")
+	from := flags.String("from", "1h", "start of the range: a `time` or how long ago, like 2h")
+	to := flags.String("to", "now", "end of the range: a `time` or how long ago")
+	flags.Parse(args)
+
+	start, end, err := parseRange(*from, *to, time.Now())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	samples, tier, err := store.Query(*dir, start, end)
+	if err != nil {
+		fmt.Printf("Error reading store: %v\n", err)
+		os.Exit(1)
+	}
+	printQuery(os.Stdout, samples, tier)
+}
+
+// printQuery writes the range covered and a table of statistics
+func printQuery(w io.Writer, samples []store.Sample, tier store.Tier) {
+	if len(samples) == 0 {
+		fmt.Fprintln(w, "No stored metrics in this range")
+		return
+	}
+	fmt.Fprintf(w, "%s to %s, %d samples every %s\n\n",
+		samples[0].Time.Format(time.DateTime), samples[len(samples)-1].Time.Format(time.DateTime), len(samples), tier.Step)
+	fmt.Fprintf(w, "%-12s %8s %8s %8s %8s\n", "metric", "min", "avg", "p95", "max")
+	for _, stat := range store.Summarize(samples) {
+		fmt.Fprintf(w, "%-12s %7.1f%% %7.1f%% %7.1f%% %7.1f%%\n", stat.Name, stat.Min, stat.Avg, stat.P95, stat.Max)
+	}
+}
+
+// parseRange parses the start and end of a time range
+func parseRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	start, err := parseTime(from, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parseTime(to, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%s is not before %s", from, to)
+	}
+	return start, end, nil
+}
+
+// timeLayouts are the accepted absolute times, in local time unless they
+// have a zone; those without a date are today
+var timeLayouts = []string{time.RFC3339, time.DateTime, "2006-01-02 15:04", time.DateOnly, time.TimeOnly, "15:04"}
+
+// parseTime accepts "now", a duration meaning that long ago (2h or -2h), or
+// one of timeLayouts
+func parseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "now" {
+		return now, nil
+	}
+	if ago, err := time.ParseDuration(strings.TrimPrefix(value, "-")); err == nil {
+		return now.Add(-ago), nil
+	}
+	for _, layout := range timeLayouts {
+		t, err := time.ParseInLocation(layout, value, now.Location())
+		if err != nil {
+			continue
+		}
+		if layout == time.TimeOnly || layout == "15:04" {
+			year, month, day := now.Date()
+			t = time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: want now, a duration like 2h, or a time like 2006-01-02 15:04", value)
+}
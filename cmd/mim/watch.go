@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+// watchList builds the watch list of -pid, -pid-file and -pgrep; nil when
+// none is set
+func watchList(pids, pidFile, pgrep string) (*domain.WatchList, error) {
+	if pids == "" && pidFile == "" && pgrep == "" {
+		return nil, nil
+	}
+	fields := splitList(pids)
+	if pidFile != "" {
+		data, err := os.ReadFile(pidFile)
+		if err != nil {
+			return nil, err
+		}
+		// One PID per line, as daemons write them, or several on a line
+		fields = append(fields, strings.Fields(strings.ReplaceAll(string(data), ",", " "))...)
+	}
+	var watched []uint32
+	for _, field := range fields {
+		pid, err := strconv.ParseUint(field, 10, 32)
+		if err != nil || pid == 0 {
+			return nil, fmt.Errorf("invalid PID %q", field)
+		}
+		watched = append(watched, uint32(pid))
+	}
+	var pattern *regexp.Regexp
+	if pgrep != "" {
+		var err error
+		if pattern, err = regexp.Compile(pgrep); err != nil {
+			return nil, fmt.Errorf("invalid -pgrep pattern: %v", err)
+		}
+	}
+	return domain.NewWatchList(watched, pattern), nil
+}
@@ -0,0 +1,91 @@
+// Package config loads mim's optional JSON configuration file.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Config is the on-disk configuration. Every section is optional; command
+// line flags take precedence over values set here.
+type Config struct {
+	Collectors map[string]CollectorConfig `json:"collectors,omitempty"`
+	Layout     *LayoutConfig              `json:"layout,omitempty"`
+	// Keys remaps actions to keys, for example {"quit": ["q", "x"]}
+	Keys map[string][]string `json:"keys,omitempty"`
+}
+
+// LayoutConfig selects a preset or defines custom rows of panels. Rows, when
+// set, replace the preset entirely.
+type LayoutConfig struct {
+	Preset string      `json:"preset,omitempty"`
+	Hidden []string    `json:"hidden,omitempty"`
+	Rows   []LayoutRow `json:"rows,omitempty"`
+}
+
+// LayoutRow is one row of panels; lines fixes its height and weight shares
+// the remaining height with other weighted rows
+type LayoutRow struct {
+	Lines  int           `json:"lines,omitempty"`
+	Weight float64       `json:"weight,omitempty"`
+	Panels []LayoutPanel `json:"panels"`
+}
+
+// LayoutPanel places a named panel in a row
+type LayoutPanel struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight,omitempty"`
+	Hidden bool    `json:"hidden,omitempty"`
+}
+
+// CollectorConfig configures a single collector by name
+type CollectorConfig struct {
+	// Enabled overrides the collector's default; nil keeps the default
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/mim/config.json or the platform equivalent
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mim", "config.json")
+}
+
+// Load reads the configuration at path. A missing file yields an empty
+// configuration rather than an error.
+func Load(path string) (Config, error) {
+	var cfg Config
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// CollectorOverrides returns the collectors explicitly enabled or disabled
+func (c Config) CollectorOverrides() map[string]bool {
+	overrides := make(map[string]bool)
+	for name, collector := range c.Collectors {
+		if collector.Enabled != nil {
+			overrides[name] = *collector.Enabled
+		}
+	}
+	return overrides
+}
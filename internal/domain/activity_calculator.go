@@ -0,0 +1,60 @@
+package domain
+
+// ActivityCounters holds the raw cumulative counters and instantaneous
+// values read from /proc/loadavg and /proc/stat
+type ActivityCounters struct {
+	Load1           float64
+	Load5           float64
+	Load15          float64
+	ProcsRunning    uint64
+	ProcsBlocked    uint64
+	ContextSwitches uint64
+	Interrupts      uint64
+	Forks           uint64
+}
+
+// ActivityCalculator provides domain logic for system activity calculations
+type ActivityCalculator struct{}
+
+// NewActivityCalculator creates a new ActivityCalculator instance
+func NewActivityCalculator() *ActivityCalculator {
+	return &ActivityCalculator{}
+}
+
+// CalculateActivity converts cumulative counters into per-second rates
+// using the previous counters and the elapsed time between them
+func (a *ActivityCalculator) CalculateActivity(
+	current ActivityCounters,
+	last ActivityCounters,
+	deltaTimeSeconds float64,
+) SystemActivityMetrics {
+	return SystemActivityMetrics{
+		Load1:                 current.Load1,
+		Load5:                 current.Load5,
+		Load15:                current.Load15,
+		ProcsRunning:          current.ProcsRunning,
+		ProcsBlocked:          current.ProcsBlocked,
+		ContextSwitchesPerSec: counterRate(current.ContextSwitches, last.ContextSwitches, deltaTimeSeconds),
+		InterruptsPerSec:      counterRate(current.Interrupts, last.Interrupts, deltaTimeSeconds),
+		ForksPerSec:           counterRate(current.Forks, last.Forks, deltaTimeSeconds),
+	}
+}
+
+// LoadRatio returns the load average relative to the number of cores,
+// where 1.0 means the run queue exactly matches the available cores
+// Returns 0 if cores is <= 0
+func (a *ActivityCalculator) LoadRatio(load float64, cores int) float64 {
+	if cores <= 0 {
+		return 0
+	}
+	return load / float64(cores)
+}
+
+// counterRate calculates a per-second rate from two monotonic counters
+// Returns 0 if deltaTimeSeconds is <= 0 or if the counter went backwards
+func counterRate(current, last uint64, deltaTimeSeconds float64) float64 {
+	if deltaTimeSeconds <= 0 || current < last {
+		return 0
+	}
+	return float64(current-last) / deltaTimeSeconds
+}
@@ -11,6 +11,27 @@ func (c CPUTimes) Total() float64 {
 	return c.User + c.System
 }
 
+// SystemCPUTimes represents cumulative CPU time counters for a core or the
+// whole system, in seconds, as reported by the kernel. As on Linux, User and
+// Nice include Guest and GuestNice respectively.
+type SystemCPUTimes struct {
+	User      float64
+	Nice      float64
+	System    float64
+	Idle      float64
+	Iowait    float64
+	Irq       float64
+	Softirq   float64
+	Steal     float64
+	Guest     float64
+	GuestNice float64
+}
+
+// Total returns the total elapsed CPU time, counting guest time once
+func (s SystemCPUTimes) Total() float64 {
+	return s.User + s.Nice + s.System + s.Idle + s.Iowait + s.Irq + s.Softirq + s.Steal
+}
+
 // CPUCalculator provides domain logic for CPU-related calculations
 type CPUCalculator struct{}
 
@@ -40,4 +61,47 @@ func (c *CPUCalculator) CalculateCPUPercent(
 	}
 
 	return (cpuDelta / deltaTimeSeconds) * 100.0
-}
\ No newline at end of file
+}
+
+// CalculateTimeBreakdown calculates the share of each CPU time bucket over
+// the interval between lastTimes and currentTimes
+// Returns a zero breakdown if no time has elapsed or the counters went backwards
+func (c *CPUCalculator) CalculateTimeBreakdown(currentTimes, lastTimes SystemCPUTimes) CPUTimeBreakdown {
+	totalDelta := currentTimes.Total() - lastTimes.Total()
+	if totalDelta <= 0 {
+		return CPUTimeBreakdown{}
+	}
+
+	percent := func(current, last float64) float64 {
+		delta := current - last
+		if delta <= 0 {
+			return 0
+		}
+		return delta / totalDelta * 100.0
+	}
+
+	guest := percent(currentTimes.Guest+currentTimes.GuestNice, lastTimes.Guest+lastTimes.GuestNice)
+	return CPUTimeBreakdown{
+		User:    percent(currentTimes.User-currentTimes.Guest, lastTimes.User-lastTimes.Guest),
+		Nice:    percent(currentTimes.Nice-currentTimes.GuestNice, lastTimes.Nice-lastTimes.GuestNice),
+		System:  percent(currentTimes.System, lastTimes.System),
+		Iowait:  percent(currentTimes.Iowait, lastTimes.Iowait),
+		Irq:     percent(currentTimes.Irq, lastTimes.Irq),
+		Softirq: percent(currentTimes.Softirq, lastTimes.Softirq),
+		Steal:   percent(currentTimes.Steal, lastTimes.Steal),
+		Guest:   guest,
+		Idle:    percent(currentTimes.Idle, lastTimes.Idle),
+	}
+}
+
+// CalculateTimeBreakdowns calculates breakdowns for a set of cores
+// Cores without a previous sample get a zero breakdown
+func (c *CPUCalculator) CalculateTimeBreakdowns(currentTimes, lastTimes []SystemCPUTimes) []CPUTimeBreakdown {
+	breakdowns := make([]CPUTimeBreakdown, len(currentTimes))
+	for i := range currentTimes {
+		if i < len(lastTimes) {
+			breakdowns[i] = c.CalculateTimeBreakdown(currentTimes[i], lastTimes[i])
+		}
+	}
+	return breakdowns
+}
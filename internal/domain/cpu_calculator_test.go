@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCPUCalculatorCalculateTimeBreakdown(t *testing.T) {
+	last := SystemCPUTimes{User: 100, Nice: 10, System: 50, Idle: 1000, Iowait: 5, Irq: 1, Softirq: 2, Steal: 3, Guest: 20, GuestNice: 4}
+	tests := []struct {
+		name    string
+		current SystemCPUTimes
+		last    SystemCPUTimes
+		want    CPUTimeBreakdown
+	}{
+		{
+			// 100 seconds elapse in total
+			name: "every bucket moves",
+			current: SystemCPUTimes{
+				User: 130, Nice: 15, System: 60, Idle: 1043, Iowait: 10, Irq: 3, Softirq: 4, Steal: 6,
+				Guest: 30, GuestNice: 6,
+			},
+			last: last,
+			want: CPUTimeBreakdown{
+				User: 20, Nice: 3, System: 10, Iowait: 5, Irq: 2, Softirq: 2, Steal: 3, Guest: 12, Idle: 43,
+			},
+		},
+		{
+			name:    "idle core",
+			current: SystemCPUTimes{User: 100, Nice: 10, System: 50, Idle: 1010, Iowait: 5, Irq: 1, Softirq: 2, Steal: 3, Guest: 20, GuestNice: 4},
+			last:    last,
+			want:    CPUTimeBreakdown{Idle: 100},
+		},
+		{
+			name:    "no time elapsed",
+			current: last,
+			last:    last,
+			want:    CPUTimeBreakdown{},
+		},
+		{
+			name:    "counters went backwards",
+			current: SystemCPUTimes{Idle: 10},
+			last:    last,
+			want:    CPUTimeBreakdown{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewCPUCalculator().CalculateTimeBreakdown(tt.current, tt.last)
+			assert.InDeltaMapValues(t, breakdownMap(tt.want), breakdownMap(got), 1e-9)
+		})
+	}
+}
+
+func TestCPUCalculatorCalculateTimeBreakdowns(t *testing.T) {
+	current := []SystemCPUTimes{{User: 10, Idle: 10}, {User: 5}}
+	last := []SystemCPUTimes{{Idle: 20}}
+
+	breakdowns := NewCPUCalculator().CalculateTimeBreakdowns(current, last)
+
+	// The second core has no previous sample
+	assert.Equal(t, []CPUTimeBreakdown{{}, {}}, breakdowns)
+}
+
+func breakdownMap(b CPUTimeBreakdown) map[string]float64 {
+	return map[string]float64{
+		"user": b.User, "nice": b.Nice, "system": b.System, "iowait": b.Iowait, "irq": b.Irq,
+		"softirq": b.Softirq, "steal": b.Steal, "guest": b.Guest, "idle": b.Idle,
+	}
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CoreTopology describes where a logical CPU sits in the machine
+type CoreTopology struct {
+	CPU    int // logical CPU index, matching CPUUsagePerCore
+	Socket int
+	Node   int // NUMA node
+	Core   int // physical core id within the socket
+}
+
+// TopologyGroup is a set of logical CPUs sharing a socket and NUMA node,
+// ordered by physical core with SMT siblings adjacent
+type TopologyGroup struct {
+	Socket         int
+	Node           int
+	CPUs           []int
+	ThreadsPerCore int
+}
+
+// Label returns a short name for the group, such as "S0 N1"
+func (g TopologyGroup) Label() string {
+	return fmt.Sprintf("S%d N%d", g.Socket, g.Node)
+}
+
+// NUMANodeMemory holds memory usage for a single NUMA node, in bytes
+type NUMANodeMemory struct {
+	Node  int
+	Total uint64
+	Used  uint64
+}
+
+// TopologyGrouper provides domain logic for arranging cores by topology
+type TopologyGrouper struct{}
+
+// NewTopologyGrouper creates a new TopologyGrouper instance
+func NewTopologyGrouper() *TopologyGrouper {
+	return &TopologyGrouper{}
+}
+
+// Group arranges numCPUs logical CPUs by socket, then NUMA node, then
+// physical core, keeping SMT siblings adjacent. CPUs missing from topology
+// (or all CPUs when topology is empty) fall into a single trailing group in
+// logical order.
+func (t *TopologyGrouper) Group(topology []CoreTopology, numCPUs int) []TopologyGroup {
+	known := make([]CoreTopology, 0, len(topology))
+	seen := make(map[int]bool, len(topology))
+	for _, c := range topology {
+		if c.CPU >= 0 && c.CPU < numCPUs && !seen[c.CPU] {
+			known = append(known, c)
+			seen[c.CPU] = true
+		}
+	}
+
+	sort.Slice(known, func(i, j int) bool {
+		a, b := known[i], known[j]
+		if a.Socket != b.Socket {
+			return a.Socket < b.Socket
+		}
+		if a.Node != b.Node {
+			return a.Node < b.Node
+		}
+		if a.Core != b.Core {
+			return a.Core < b.Core
+		}
+		return a.CPU < b.CPU
+	})
+
+	var groups []TopologyGroup
+	for _, c := range known {
+		if len(groups) == 0 || groups[len(groups)-1].Socket != c.Socket || groups[len(groups)-1].Node != c.Node {
+			groups = append(groups, TopologyGroup{Socket: c.Socket, Node: c.Node})
+		}
+		groups[len(groups)-1].CPUs = append(groups[len(groups)-1].CPUs, c.CPU)
+	}
+
+	var unknown []int
+	for cpu := 0; cpu < numCPUs; cpu++ {
+		if !seen[cpu] {
+			unknown = append(unknown, cpu)
+		}
+	}
+	if len(unknown) > 0 {
+		groups = append(groups, TopologyGroup{Socket: -1, Node: -1, CPUs: unknown})
+	}
+
+	for i := range groups {
+		groups[i].ThreadsPerCore = threadsPerCore(known, groups[i])
+	}
+	return groups
+}
+
+// threadsPerCore returns the largest number of logical CPUs sharing a
+// physical core within the group, or 1 when unknown
+func threadsPerCore(topology []CoreTopology, group TopologyGroup) int {
+	counts := make(map[int]int)
+	most := 1
+	for _, c := range topology {
+		if c.Socket == group.Socket && c.Node == group.Node {
+			counts[c.Core]++
+			most = max(most, counts[c.Core])
+		}
+	}
+	return most
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopologyGrouperGroup(t *testing.T) {
+	// Two sockets, one node each, two cores with SMT siblings numbered
+	// the way Linux usually enumerates them (siblings are n and n+4)
+	topology := []CoreTopology{
+		{CPU: 0, Socket: 0, Node: 0, Core: 0},
+		{CPU: 1, Socket: 0, Node: 0, Core: 1},
+		{CPU: 2, Socket: 1, Node: 1, Core: 0},
+		{CPU: 3, Socket: 1, Node: 1, Core: 1},
+		{CPU: 4, Socket: 0, Node: 0, Core: 0},
+		{CPU: 5, Socket: 0, Node: 0, Core: 1},
+		{CPU: 6, Socket: 1, Node: 1, Core: 0},
+		{CPU: 7, Socket: 1, Node: 1, Core: 1},
+	}
+
+	groups := NewTopologyGrouper().Group(topology, 8)
+
+	assert.Equal(t, []TopologyGroup{
+		{Socket: 0, Node: 0, CPUs: []int{0, 4, 1, 5}, ThreadsPerCore: 2},
+		{Socket: 1, Node: 1, CPUs: []int{2, 6, 3, 7}, ThreadsPerCore: 2},
+	}, groups)
+	assert.Equal(t, "S1 N1", groups[1].Label())
+}
+
+func TestTopologyGrouperGroupWithoutTopology(t *testing.T) {
+	groups := NewTopologyGrouper().Group(nil, 3)
+
+	assert.Equal(t, []TopologyGroup{
+		{Socket: -1, Node: -1, CPUs: []int{0, 1, 2}, ThreadsPerCore: 1},
+	}, groups)
+}
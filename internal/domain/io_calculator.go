@@ -0,0 +1,26 @@
+package domain
+
+// IOCounters holds cumulative storage I/O byte counters for a process
+type IOCounters struct {
+	ReadBytes  uint64
+	WriteBytes uint64
+}
+
+// IOCalculator provides domain logic for I/O rate calculations
+type IOCalculator struct{}
+
+// NewIOCalculator creates a new IOCalculator instance
+func NewIOCalculator() *IOCalculator {
+	return &IOCalculator{}
+}
+
+// CalculateIORates calculates read and write bytes per second between two samples
+// Returns 0 rates if deltaTimeSeconds is <= 0 or the counters went backwards
+func (c *IOCalculator) CalculateIORates(
+	currentCounters IOCounters,
+	lastCounters IOCounters,
+	deltaTimeSeconds float64,
+) (readPerSec, writePerSec float64) {
+	return counterRate(currentCounters.ReadBytes, lastCounters.ReadBytes, deltaTimeSeconds),
+		counterRate(currentCounters.WriteBytes, lastCounters.WriteBytes, deltaTimeSeconds)
+}
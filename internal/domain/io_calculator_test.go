@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIOCalculatorCalculateIORates(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   IOCounters
+		last      IOCounters
+		delta     float64
+		wantRead  float64
+		wantWrite float64
+	}{
+		{
+			name:      "typical",
+			current:   IOCounters{ReadBytes: 3000, WriteBytes: 1500},
+			last:      IOCounters{ReadBytes: 1000, WriteBytes: 500},
+			delta:     2,
+			wantRead:  1000,
+			wantWrite: 500,
+		},
+		{
+			name:    "idle",
+			current: IOCounters{ReadBytes: 1000, WriteBytes: 500},
+			last:    IOCounters{ReadBytes: 1000, WriteBytes: 500},
+			delta:   1,
+		},
+		{
+			name:    "no elapsed time",
+			current: IOCounters{ReadBytes: 3000, WriteBytes: 1500},
+			last:    IOCounters{ReadBytes: 1000, WriteBytes: 500},
+		},
+		{
+			// A reused PID starts its counters again
+			name:      "counters went backwards",
+			current:   IOCounters{ReadBytes: 10, WriteBytes: 600},
+			last:      IOCounters{ReadBytes: 1000, WriteBytes: 500},
+			delta:     1,
+			wantWrite: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			read, write := NewIOCalculator().CalculateIORates(tt.current, tt.last, tt.delta)
+			assert.Equal(t, tt.wantRead, read)
+			assert.Equal(t, tt.wantWrite, write)
+		})
+	}
+}
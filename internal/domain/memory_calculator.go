@@ -0,0 +1,77 @@
+package domain
+
+// MemoryCounters holds the raw kernel memory counters needed to derive a
+// MemoryBreakdown. Cached excludes reclaimable slab.
+type MemoryCounters struct {
+	Total         uint64
+	Free          uint64
+	Used          uint64
+	Buffers       uint64
+	Cached        uint64
+	Shared        uint64
+	SlabReclaim   uint64
+	SlabUnreclaim uint64
+	PageTables    uint64
+	Dirty         uint64
+	Writeback     uint64
+	SwapTotal     uint64
+	SwapFree      uint64
+	SwapInBytes   uint64
+	SwapOutBytes  uint64
+}
+
+// MemoryCalculator provides domain logic for memory-related calculations
+type MemoryCalculator struct{}
+
+// NewMemoryCalculator creates a new MemoryCalculator instance
+func NewMemoryCalculator() *MemoryCalculator {
+	return &MemoryCalculator{}
+}
+
+// CalculateBreakdown derives the memory breakdown from raw counters.
+// Swap rates are computed against the previous counters over deltaTimeSeconds.
+func (m *MemoryCalculator) CalculateBreakdown(
+	current MemoryCounters,
+	last MemoryCounters,
+	deltaTimeSeconds float64,
+) MemoryBreakdown {
+	slab := current.SlabReclaim + current.SlabUnreclaim
+
+	return MemoryBreakdown{
+		Total:         current.Total,
+		Free:          current.Free,
+		Anonymous:     saturatingSub(current.Used, current.SlabUnreclaim+current.PageTables),
+		PageCache:     saturatingSub(current.Cached, current.Shared),
+		Buffers:       current.Buffers,
+		Shared:        current.Shared,
+		Slab:          slab,
+		Dirty:         current.Dirty,
+		Writeback:     current.Writeback,
+		SwapTotal:     current.SwapTotal,
+		SwapUsed:      saturatingSub(current.SwapTotal, current.SwapFree),
+		SwapInPerSec:  m.CalculateRate(current.SwapInBytes, last.SwapInBytes, deltaTimeSeconds),
+		SwapOutPerSec: m.CalculateRate(current.SwapOutBytes, last.SwapOutBytes, deltaTimeSeconds),
+	}
+}
+
+// CalculateRate calculates a per-second rate from two monotonic counters
+// Returns 0 if deltaTimeSeconds is <= 0 or if the counter went backwards
+func (m *MemoryCalculator) CalculateRate(current, last uint64, deltaTimeSeconds float64) float64 {
+	return counterRate(current, last, deltaTimeSeconds)
+}
+
+// CalculatePercent calculates what percentage part is of total
+// Returns 0 if total is 0 to avoid division by zero
+func (m *MemoryCalculator) CalculatePercent(part, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return (float64(part) / float64(total)) * 100.0
+}
+
+func saturatingSub(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
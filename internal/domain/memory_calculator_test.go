@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCalculatorCalculateBreakdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		current MemoryCounters
+		last    MemoryCounters
+		delta   float64
+		want    MemoryBreakdown
+	}{
+		{
+			name: "typical",
+			current: MemoryCounters{
+				Total: 1000, Free: 100, Used: 500, Buffers: 50, Cached: 300, Shared: 40,
+				SlabReclaim: 30, SlabUnreclaim: 20, PageTables: 10, Dirty: 5, Writeback: 1,
+				SwapTotal: 400, SwapFree: 300, SwapInBytes: 4096, SwapOutBytes: 2048,
+			},
+			last:  MemoryCounters{SwapInBytes: 0, SwapOutBytes: 1024},
+			delta: 2,
+			want: MemoryBreakdown{
+				Total: 1000, Free: 100, Anonymous: 470, PageCache: 260, Buffers: 50, Shared: 40,
+				Slab: 50, Dirty: 5, Writeback: 1, SwapTotal: 400, SwapUsed: 100,
+				SwapInPerSec: 2048, SwapOutPerSec: 512,
+			},
+		},
+		{
+			name:    "underflowing counters clamp to zero",
+			current: MemoryCounters{Used: 10, SlabUnreclaim: 20, Cached: 5, Shared: 10, SwapFree: 8},
+			want:    MemoryBreakdown{Shared: 10, Slab: 20},
+		},
+		{
+			name:    "counter reset gives no rate",
+			current: MemoryCounters{SwapInBytes: 10},
+			last:    MemoryCounters{SwapInBytes: 100},
+			delta:   1,
+			want:    MemoryBreakdown{},
+		},
+		{
+			name:    "no elapsed time gives no rate",
+			current: MemoryCounters{SwapOutBytes: 100},
+			want:    MemoryBreakdown{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewMemoryCalculator().CalculateBreakdown(tt.current, tt.last, tt.delta)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
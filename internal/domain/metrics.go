@@ -3,22 +3,122 @@ package domain
 type CPUMemoryMetrics struct {
 	CPUUsagePerCore []float64
 	CPUUsageTotal   float64
+	CPUTimesPerCore []CPUTimeBreakdown
+	CPUTimesTotal   CPUTimeBreakdown
+	Topology        []CoreTopology
+	CoreFrequencies []CoreFrequency
 	MemoryUsage     float64
+	Memory          MemoryBreakdown
+	NUMAMemory      []NUMANodeMemory
 	Processes       []CPUProcessInfo
 }
 
+// CPUTimeBreakdown splits CPU time into the kernel's accounting buckets, as
+// percentages of the elapsed interval. User and Nice exclude guest time.
+type CPUTimeBreakdown struct {
+	User    float64
+	Nice    float64
+	System  float64
+	Iowait  float64
+	Irq     float64
+	Softirq float64
+	Steal   float64
+	Guest   float64
+	Idle    float64
+}
+
+// CoreFrequency holds cpufreq state for one logical CPU. Frequencies are in
+// MHz; zero means the value was not reported.
+type CoreFrequency struct {
+	CPU         int
+	CurrentMHz  float64
+	MinMHz      float64 // scaling_min_freq, the policy lower limit
+	MaxMHz      float64 // scaling_max_freq, the policy upper limit
+	HardwareMax float64 // cpuinfo_max_freq, including boost where exposed
+	Governor    string
+}
+
+// MemoryBreakdown splits system memory into its main consumers. Sizes are in
+// bytes; swap rates are in bytes per second.
+type MemoryBreakdown struct {
+	Total         uint64
+	Free          uint64
+	Anonymous     uint64
+	PageCache     uint64
+	Buffers       uint64
+	Shared        uint64
+	Slab          uint64
+	Dirty         uint64
+	Writeback     uint64
+	SwapTotal     uint64
+	SwapUsed      uint64
+	SwapInPerSec  float64
+	SwapOutPerSec float64
+}
+
+// SystemActivityMetrics holds scheduler-level activity: load averages, the
+// current run queue and per-second rates of kernel events.
+type SystemActivityMetrics struct {
+	Load1                 float64
+	Load5                 float64
+	Load15                float64
+	ProcsRunning          uint64
+	ProcsBlocked          uint64
+	ContextSwitchesPerSec float64
+	InterruptsPerSec      float64
+	ForksPerSec           float64
+}
+
+// DiskNetMetrics holds per-device storage and per-interface network
+// throughput.
+type DiskNetMetrics struct {
+	Disks      []DeviceIORate
+	Interfaces []InterfaceIORate
+}
+
+// DeviceIORate is the throughput of one block device
+type DeviceIORate struct {
+	Name             string
+	ReadBytesPerSec  float64
+	WriteBytesPerSec float64
+}
+
+// InterfaceIORate is the throughput of one network interface
+type InterfaceIORate struct {
+	Name            string
+	RecvBytesPerSec float64
+	SentBytesPerSec float64
+}
+
 type CPUProcessInfo struct {
 	Pid           uint32
+	PPid          uint32 // parent process
 	CPUPercent    float64
 	MemoryPercent float64
 	Command       string
 	User          string
+	// Disk I/O from /proc/[pid]/io. IOKnown is false when the counters
+	// could not be read, typically for other users' processes.
+	IOKnown          bool
+	ReadBytesPerSec  float64
+	WriteBytesPerSec float64
 }
 
 type GPUMetrics struct {
 	GPUUsage       float64
 	GPUMemoryUsage float64
 	Processes      []GPUProcessInfo
+	// Devices holds utilization for every GPU; the fields above describe
+	// the first
+	Devices []GPUDeviceMetrics
+}
+
+// GPUDeviceMetrics is the utilization of one GPU, in percent
+type GPUDeviceMetrics struct {
+	Index       int
+	UUID        string
+	Usage       float64
+	MemoryUsage float64
 }
 
 type GPUProcessInfo struct {
@@ -27,3 +127,35 @@ type GPUProcessInfo struct {
 	UsedGpuMemory float64
 	User          string
 }
+
+// PressureStats holds one line of a Linux PSI file: the share of wall time
+// (in percent) that tasks were stalled, averaged over 10s, 60s and 300s,
+// plus the cumulative stall time in microseconds.
+type PressureStats struct {
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	Total  uint64
+}
+
+// ResourcePressure holds the "some" and "full" stall lines for a resource.
+// CPU pressure has no meaningful "full" line on most kernels.
+type ResourcePressure struct {
+	Some PressureStats
+	Full PressureStats
+}
+
+// PressureSet holds pressure for the three resources the kernel tracks
+type PressureSet struct {
+	CPU    ResourcePressure
+	Memory ResourcePressure
+	IO     ResourcePressure
+}
+
+type PSIMetrics struct {
+	Available       bool
+	System          PressureSet
+	Cgroup          string
+	CgroupAvailable bool
+	CgroupPressure  PressureSet
+}
@@ -0,0 +1,88 @@
+package domain
+
+// ProcessTree follows a process and its descendants across samples. A
+// process joins when its parent is a member and stays one while it is
+// alive, so children re-parented after their parent exits are kept.
+// Processes that fork and exit between two samples cannot be seen.
+type ProcessTree struct {
+	root    uint32
+	members map[uint32]bool
+}
+
+// NewProcessTree follows root and every process it starts
+func NewProcessTree(root uint32) *ProcessTree {
+	return &ProcessTree{root: root, members: map[uint32]bool{root: true}}
+}
+
+func (t *ProcessTree) Root() uint32 { return t.root }
+
+// Len returns the number of live processes in the tree
+func (t *ProcessTree) Len() int { return len(t.members) }
+
+func (t *ProcessTree) Contains(pid uint32) bool { return t.members[pid] }
+
+// Update replaces the members with those alive in processes, adding the
+// children of members
+func (t *ProcessTree) Update(processes []CPUProcessInfo) {
+	members := map[uint32]bool{t.root: true}
+	for _, p := range processes {
+		if t.members[p.Pid] {
+			members[p.Pid] = true
+		}
+	}
+	// Each pass reaches one generation further down
+	for added := true; added; {
+		added = false
+		for _, p := range processes {
+			if !members[p.Pid] && members[p.PPid] {
+				members[p.Pid] = true
+				added = true
+			}
+		}
+	}
+	t.members = members
+}
+
+// Focus narrows a collector value to the tree. CPU samples update the
+// members first. Process lists keep only members; total CPU, memory and GPU
+// usage become the sums over the members, CPU normalized by the core count
+// and GPU by the device count like the machine-wide values. Per-core and
+// breakdown values still describe the machine. Other values are returned
+// unchanged.
+func (t *ProcessTree) Focus(value any) any {
+	switch m := value.(type) {
+	case CPUMemoryMetrics:
+		t.Update(m.Processes)
+		processes := make([]CPUProcessInfo, 0, t.Len())
+		var cpu, memory float64
+		for _, p := range m.Processes {
+			if t.members[p.Pid] {
+				processes = append(processes, p)
+				cpu += p.CPUPercent
+				memory += p.MemoryPercent
+			}
+		}
+		m.Processes = processes
+		m.CPUUsageTotal = min(100, cpu/float64(max(1, len(m.CPUUsagePerCore))))
+		m.MemoryUsage = memory
+		return m
+	case GPUMetrics:
+		processes := make([]GPUProcessInfo, 0)
+		var usage, memory float64
+		for _, p := range m.Processes {
+			if t.members[p.Pid] {
+				processes = append(processes, p)
+				usage += float64(p.SmUtil)
+				memory += p.UsedGpuMemory
+			}
+		}
+		devices := float64(max(1, len(m.Devices)))
+		m.Processes = processes
+		m.GPUUsage = min(100, usage/devices)
+		m.GPUMemoryUsage = min(100, memory/devices)
+		// Process usage is not attributed to devices
+		m.Devices = nil
+		return m
+	}
+	return value
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessTree(t *testing.T) {
+	tree := NewProcessTree(10)
+	cpu := tree.Focus(CPUMemoryMetrics{
+		CPUUsagePerCore: []float64{50, 50},
+		CPUUsageTotal:   50,
+		MemoryUsage:     80,
+		Processes: []CPUProcessInfo{
+			{Pid: 1, PPid: 0, CPUPercent: 10, MemoryPercent: 1},
+			{Pid: 10, PPid: 1, CPUPercent: 20, MemoryPercent: 2},
+			// Listed before its parent, which joins in the same sample
+			{Pid: 12, PPid: 11, CPUPercent: 40, MemoryPercent: 4},
+			{Pid: 11, PPid: 10, CPUPercent: 60, MemoryPercent: 3},
+			{Pid: 20, PPid: 1, CPUPercent: 90, MemoryPercent: 9},
+		},
+	}).(CPUMemoryMetrics)
+
+	assert.Equal(t, 3, tree.Len())
+	assert.Len(t, cpu.Processes, 3)
+	assert.Equal(t, 60.0, cpu.CPUUsageTotal) // 120% over two cores
+	assert.Equal(t, 9.0, cpu.MemoryUsage)
+	assert.Equal(t, []float64{50, 50}, cpu.CPUUsagePerCore)
+
+	// 11 exits; its child, re-parented to 1, stays in the tree
+	tree.Focus(CPUMemoryMetrics{Processes: []CPUProcessInfo{
+		{Pid: 1}, {Pid: 10, PPid: 1}, {Pid: 12, PPid: 1}, {Pid: 20, PPid: 1},
+	}})
+	assert.True(t, tree.Contains(12))
+	assert.False(t, tree.Contains(11))
+	assert.False(t, tree.Contains(20))
+
+	gpu := tree.Focus(GPUMetrics{
+		GPUUsage: 90,
+		Processes: []GPUProcessInfo{
+			{Pid: 12, SmUtil: 60, UsedGpuMemory: 30},
+			{Pid: 20, SmUtil: 30, UsedGpuMemory: 10},
+		},
+		Devices: []GPUDeviceMetrics{{Index: 0, Usage: 90}, {Index: 1}},
+	}).(GPUMetrics)
+	assert.Equal(t, []GPUProcessInfo{{Pid: 12, SmUtil: 60, UsedGpuMemory: 30}}, gpu.Processes)
+	assert.Equal(t, 30.0, gpu.GPUUsage)
+	assert.Equal(t, 15.0, gpu.GPUMemoryUsage)
+	assert.Nil(t, gpu.Devices)
+
+	assert.Equal(t, "other", tree.Focus("other"))
+}
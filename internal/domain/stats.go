@@ -0,0 +1,277 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// digestCompression scales the number of centroids a Stats keeps; higher
+	// is more accurate
+	digestCompression = 100
+	// digestBuffer is how many values are collected before they are merged
+	digestBuffer = 500
+	// maxExitedProcesses bounds the processes a RunStats keeps after they
+	// exit; those with the lowest usage are dropped first
+	maxExitedProcesses = 200
+)
+
+// centroid is the mean of weight values
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// Stats accumulates one series: exact count, mean, min and max, and
+// percentiles approximated with a merging t-digest. The digest keeps a few
+// hundred centroids, growing with the logarithm of the count. Centroids are
+// small near the tails, so high percentiles stay accurate; with few values
+// every value is kept and percentiles are exact.
+type Stats struct {
+	count     int
+	sum       float64
+	min       float64
+	max       float64
+	centroids []centroid
+	buffer    []float64
+}
+
+// NewStats creates an empty accumulator
+func NewStats() *Stats {
+	return &Stats{}
+}
+
+// Add records a value
+func (s *Stats) Add(v float64) {
+	if s.count == 0 || v < s.min {
+		s.min = v
+	}
+	if s.count == 0 || v > s.max {
+		s.max = v
+	}
+	s.count++
+	s.sum += v
+	s.buffer = append(s.buffer, v)
+	if len(s.buffer) >= digestBuffer {
+		s.compress()
+	}
+}
+
+func (s *Stats) Count() int   { return s.count }
+func (s *Stats) Min() float64 { return s.min }
+func (s *Stats) Max() float64 { return s.max }
+
+// Mean returns the average; 0 when empty
+func (s *Stats) Mean() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return s.sum / float64(s.count)
+}
+
+// Percentile returns the nearest-rank value at p percent, for example 95;
+// 0 when empty
+func (s *Stats) Percentile(p float64) float64 {
+	if s.count == 0 {
+		return 0
+	}
+	s.compress()
+	rank := math.Max(1, math.Ceil(p/100*float64(s.count)))
+	seen := 0.0
+	for _, c := range s.centroids {
+		seen += c.weight
+		if seen >= rank {
+			return math.Max(s.min, math.Min(s.max, c.mean))
+		}
+	}
+	return s.max
+}
+
+// compress merges the buffered values into the centroids. Neighbouring
+// centroids are combined while their weight stays under a limit that is
+// largest at the median and shrinks towards both tails.
+func (s *Stats) compress() {
+	if len(s.buffer) == 0 {
+		return
+	}
+	all := s.centroids
+	for _, v := range s.buffer {
+		all = append(all, centroid{mean: v, weight: 1})
+	}
+	s.buffer = s.buffer[:0]
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	total := float64(s.count)
+	merged := make([]centroid, 0, len(all))
+	current := all[0]
+	before := 0.0 // weight of the centroids already emitted
+	for _, next := range all[1:] {
+		weight := current.weight + next.weight
+		q := (before + weight/2) / total
+		if weight <= math.Max(1, 4*total*q*(1-q)/digestCompression) {
+			current.mean += (next.mean - current.mean) * next.weight / weight
+			current.weight = weight
+			continue
+		}
+		merged = append(merged, current)
+		before += current.weight
+		current = next
+	}
+	s.centroids = append(merged, current)
+}
+
+// ProcessStats is the usage of one process over a run
+type ProcessStats struct {
+	Pid     uint32
+	User    string
+	Command string
+	CPU     *Stats
+	Memory  *Stats
+	GPU     *Stats // SM utilization
+}
+
+// processKey identifies a process over a run; a PID reused by another
+// command is another process
+type processKey struct {
+	pid     uint32
+	command string
+}
+
+// RunStats keeps Stats for every series of a run: total CPU, each core,
+// memory, utilization and memory of each GPU, and each process. A process's
+// series only count the samples it appears in. Processes that have exited
+// are kept up to maxExitedProcesses.
+type RunStats struct {
+	series    map[string]*Stats
+	order     []string
+	processes map[processKey]*ProcessStats
+	running   map[uint32]*ProcessStats // by PID, as of the latest CPU sample
+}
+
+func NewRunStats() *RunStats {
+	return &RunStats{
+		series:    make(map[string]*Stats),
+		processes: make(map[processKey]*ProcessStats),
+		running:   make(map[uint32]*ProcessStats),
+	}
+}
+
+// Add records a collector sample; other values are ignored
+func (r *RunStats) Add(value any) {
+	switch m := value.(type) {
+	case CPUMemoryMetrics:
+		r.add("cpu", m.CPUUsageTotal)
+		r.add("memory", m.MemoryUsage)
+		for i, usage := range m.CPUUsagePerCore {
+			r.add(fmt.Sprintf("cpu%d", i), usage)
+		}
+		running := make(map[uint32]*ProcessStats, len(m.Processes))
+		for _, p := range m.Processes {
+			ps := r.process(p.Pid, p.Command)
+			ps.User = p.User
+			ps.CPU.Add(p.CPUPercent)
+			ps.Memory.Add(p.MemoryPercent)
+			running[p.Pid] = ps
+		}
+		r.running = running
+		r.pruneExited()
+	case GPUMetrics:
+		devices := m.Devices
+		if len(devices) == 0 {
+			devices = []GPUDeviceMetrics{{Usage: m.GPUUsage, MemoryUsage: m.GPUMemoryUsage}}
+		}
+		for _, d := range devices {
+			r.add(fmt.Sprintf("gpu%d", d.Index), d.Usage)
+			r.add(fmt.Sprintf("gpu%d memory", d.Index), d.MemoryUsage)
+		}
+		for _, p := range m.Processes {
+			// GPU samples carry no command, so they go to the process the
+			// CPU collector last saw with that PID
+			ps, ok := r.running[p.Pid]
+			if !ok {
+				ps = r.process(p.Pid, "")
+			}
+			if ps.User == "" {
+				ps.User = p.User
+			}
+			ps.GPU.Add(float64(p.SmUtil))
+		}
+	}
+}
+
+func (r *RunStats) add(name string, v float64) {
+	s, ok := r.series[name]
+	if !ok {
+		s = NewStats()
+		r.series[name] = s
+		r.order = append(r.order, name)
+	}
+	s.Add(v)
+}
+
+func (r *RunStats) process(pid uint32, command string) *ProcessStats {
+	key := processKey{pid: pid, command: command}
+	p, ok := r.processes[key]
+	if !ok {
+		p = &ProcessStats{Pid: pid, Command: command, CPU: NewStats(), Memory: NewStats(), GPU: NewStats()}
+		r.processes[key] = p
+	}
+	return p
+}
+
+// pruneExited drops the exited processes with the lowest usage beyond
+// maxExitedProcesses
+func (r *RunStats) pruneExited() {
+	var exited []processKey
+	for key, p := range r.processes {
+		if r.running[key.pid] != p {
+			exited = append(exited, key)
+		}
+	}
+	if len(exited) <= maxExitedProcesses {
+		return
+	}
+	sort.Slice(exited, func(i, j int) bool {
+		return r.processes[exited[i]].usage() < r.processes[exited[j]].usage()
+	})
+	for _, key := range exited[:len(exited)-maxExitedProcesses] {
+		delete(r.processes, key)
+	}
+}
+
+// usage is what processes are ranked by: average CPU plus GPU utilization
+func (p *ProcessStats) usage() float64 {
+	return p.CPU.Mean() + p.GPU.Mean()
+}
+
+// Series returns the name of every series in the order first seen, which
+// puts total CPU and memory before the cores
+func (r *RunStats) Series() []string {
+	return r.order
+}
+
+// Get returns the named series; nil when it has no samples
+func (r *RunStats) Get(name string) *Stats {
+	return r.series[name]
+}
+
+// Processes returns the n processes with the highest average CPU plus GPU
+// utilization; all of them when n is 0
+func (r *RunStats) Processes(n int) []*ProcessStats {
+	processes := make([]*ProcessStats, 0, len(r.processes))
+	for _, p := range r.processes {
+		processes = append(processes, p)
+	}
+	sort.Slice(processes, func(i, j int) bool {
+		a, b := processes[i].usage(), processes[j].usage()
+		if a != b {
+			return a > b
+		}
+		return processes[i].Pid < processes[j].Pid
+	})
+	if n > 0 && n < len(processes) {
+		processes = processes[:n]
+	}
+	return processes
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	s := NewStats()
+	assert.Equal(t, 0.0, s.Percentile(95))
+	for _, v := range []float64{5, 1, 4, 2, 3} {
+		s.Add(v)
+	}
+	assert.Equal(t, 5, s.Count())
+	assert.Equal(t, 1.0, s.Min())
+	assert.Equal(t, 5.0, s.Max())
+	assert.Equal(t, 3.0, s.Mean())
+	// Few values are kept exactly
+	assert.Equal(t, 5.0, s.Percentile(95))
+	assert.Equal(t, 3.0, s.Percentile(50))
+
+	// Many values are approximated in little memory, most closely at the tails
+	rng := rand.New(rand.NewSource(1))
+	s = NewStats()
+	for range 100000 {
+		s.Add(rng.Float64() * 100)
+	}
+	assert.InDelta(t, 95, s.Percentile(95), 0.5)
+	assert.InDelta(t, 99, s.Percentile(99), 0.2)
+	assert.InDelta(t, 50, s.Percentile(50), 2)
+	assert.Less(t, len(s.centroids), 1000)
+}
+
+func TestRunStats(t *testing.T) {
+	r := NewRunStats()
+	r.Add(CPUMemoryMetrics{CPUUsageTotal: 10, MemoryUsage: 50, CPUUsagePerCore: []float64{5, 15},
+		Processes: []CPUProcessInfo{{Pid: 1, CPUPercent: 10, Command: "idle"}, {Pid: 2, CPUPercent: 5, Command: "train"}}})
+	r.Add(GPUMetrics{Devices: []GPUDeviceMetrics{{Index: 0, Usage: 90}}, Processes: []GPUProcessInfo{{Pid: 2, SmUtil: 90}}})
+	r.Add(CPUMemoryMetrics{CPUUsageTotal: 30, MemoryUsage: 50, CPUUsagePerCore: []float64{25, 35}})
+	r.Add("not a sample")
+
+	assert.Equal(t, []string{"cpu", "memory", "cpu0", "cpu1", "gpu0", "gpu0 memory"}, r.Series())
+	assert.Equal(t, 20.0, r.Get("cpu").Mean())
+	assert.Equal(t, 35.0, r.Get("cpu1").Max())
+	assert.Nil(t, r.Get("gpu1"))
+
+	processes := r.Processes(0)
+	assert.Equal(t, []uint32{2, 1}, []uint32{processes[0].Pid, processes[1].Pid})
+	assert.Equal(t, "train", processes[0].Command)
+	assert.Equal(t, 90.0, processes[0].GPU.Max())
+	assert.Len(t, r.Processes(1), 1)
+}
+
+func TestRunStatsProcessLifetimes(t *testing.T) {
+	r := NewRunStats()
+	r.Add(CPUMemoryMetrics{Processes: []CPUProcessInfo{{Pid: 7, CPUPercent: 80, Command: "compile"}}})
+	// The PID is reused by another command, which starts its own series
+	r.Add(CPUMemoryMetrics{Processes: []CPUProcessInfo{{Pid: 7, CPUPercent: 2, Command: "sh"}}})
+	r.Add(GPUMetrics{Processes: []GPUProcessInfo{{Pid: 7, SmUtil: 10}}})
+
+	processes := r.Processes(0)
+	assert.Len(t, processes, 2)
+	assert.Equal(t, "compile", processes[0].Command)
+	assert.Equal(t, 1, processes[0].CPU.Count())
+	assert.Equal(t, "sh", processes[1].Command)
+	assert.Equal(t, 10.0, processes[1].GPU.Max())
+
+	// Short-lived processes beyond the limit are dropped, least busy first,
+	// while running ones are kept
+	for i := range maxExitedProcesses + 10 {
+		r.Add(CPUMemoryMetrics{Processes: []CPUProcessInfo{
+			{Pid: 1, CPUPercent: 0, Command: "init"},
+			{Pid: uint32(1000 + i), CPUPercent: float64(i) / 10, Command: "job"},
+		}})
+	}
+	processes = r.Processes(0)
+	assert.Len(t, processes, maxExitedProcesses+2)
+	assert.Equal(t, "compile", processes[0].Command)
+	assert.Equal(t, uint32(1000+maxExitedProcesses+9), processes[1].Pid)
+	assert.Equal(t, uint32(1), processes[len(processes)-1].Pid)
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"regexp"
+	"slices"
+)
+
+// maxExitedMatches bounds the exited processes that joined a WatchList by
+// its pattern; the oldest are dropped first
+const maxExitedMatches = 10
+
+// WatchedProcess is the latest state of a watched process
+type WatchedProcess struct {
+	Pid           uint32
+	User          string
+	Command       string
+	CPUPercent    float64
+	MemoryPercent float64
+	GPUPercent    float64 // SM utilization
+	Seen          bool    // has appeared in a sample
+	Exited        bool    // was seen, then missing from a sample
+}
+
+// WatchList pins processes by PID and by a pattern matched against command
+// names. Processes matching the pattern join as they appear. Processes stay
+// listed after they exit, up to maxExitedMatches for those that matched the
+// pattern.
+type WatchList struct {
+	pids      []uint32
+	pattern   *regexp.Regexp
+	processes []*WatchedProcess
+	byPid     map[uint32]*WatchedProcess
+}
+
+// NewWatchList watches pids and, when pattern is not nil, every process
+// whose command matches it
+func NewWatchList(pids []uint32, pattern *regexp.Regexp) *WatchList {
+	w := &WatchList{pids: pids, pattern: pattern, byPid: make(map[uint32]*WatchedProcess)}
+	for _, pid := range pids {
+		w.add(pid)
+	}
+	return w
+}
+
+// Empty returns a list watching the same PIDs and pattern that has seen no
+// samples
+func (w *WatchList) Empty() *WatchList {
+	return NewWatchList(w.pids, w.pattern)
+}
+
+func (w *WatchList) add(pid uint32) *WatchedProcess {
+	if p, ok := w.byPid[pid]; ok {
+		return p
+	}
+	p := &WatchedProcess{Pid: pid}
+	w.byPid[pid] = p
+	w.processes = append(w.processes, p)
+	return p
+}
+
+// Processes returns the watched processes in the order they were added
+func (w *WatchList) Processes() []*WatchedProcess {
+	return w.processes
+}
+
+// Update records a collector sample; other values are ignored. A PID is not
+// watched again once its process has exited, even if the PID is reused,
+// until its row is dropped.
+func (w *WatchList) Update(value any) {
+	switch m := value.(type) {
+	case CPUMemoryMetrics:
+		alive := make(map[uint32]bool, len(w.processes))
+		for _, p := range m.Processes {
+			watched := w.byPid[p.Pid]
+			if watched == nil && w.pattern != nil && w.pattern.MatchString(p.Command) {
+				watched = w.add(p.Pid)
+			}
+			if watched == nil || watched.Exited {
+				continue
+			}
+			alive[p.Pid] = true
+			watched.Seen = true
+			watched.User, watched.Command = p.User, p.Command
+			watched.CPUPercent, watched.MemoryPercent = p.CPUPercent, p.MemoryPercent
+		}
+		for _, watched := range w.processes {
+			if watched.Seen && !watched.Exited && !alive[watched.Pid] {
+				watched.Exited = true
+				watched.CPUPercent, watched.MemoryPercent, watched.GPUPercent = 0, 0, 0
+			}
+		}
+		w.pruneExited()
+	case GPUMetrics:
+		usage := make(map[uint32]float64, len(m.Processes))
+		for _, p := range m.Processes {
+			usage[p.Pid] += float64(p.SmUtil)
+		}
+		for _, watched := range w.processes {
+			if !watched.Exited {
+				watched.GPUPercent = usage[watched.Pid]
+			}
+		}
+	}
+}
+
+// pruneExited drops the oldest exited processes that matched the pattern
+// beyond maxExitedMatches. Processes watched by PID are kept.
+func (w *WatchList) pruneExited() {
+	exited := 0
+	for _, watched := range w.processes {
+		if watched.Exited && !slices.Contains(w.pids, watched.Pid) {
+			exited++
+		}
+	}
+	if exited <= maxExitedMatches {
+		return
+	}
+	w.processes = slices.DeleteFunc(w.processes, func(watched *WatchedProcess) bool {
+		if exited > maxExitedMatches && watched.Exited && !slices.Contains(w.pids, watched.Pid) {
+			exited--
+			delete(w.byPid, watched.Pid)
+			return true
+		}
+		return false
+	})
+}
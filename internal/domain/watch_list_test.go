@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchList(t *testing.T) {
+	w := NewWatchList([]uint32{7}, regexp.MustCompile(`^train`))
+	w.Update(CPUMemoryMetrics{Processes: []CPUProcessInfo{
+		{Pid: 7, Command: "redis", CPUPercent: 5},
+		{Pid: 8, Command: "trainer", CPUPercent: 90, MemoryPercent: 20},
+		{Pid: 9, Command: "bash"},
+	}})
+	w.Update(GPUMetrics{Processes: []GPUProcessInfo{{Pid: 8, SmUtil: 40}, {Pid: 8, SmUtil: 20}}})
+
+	processes := w.Processes()
+	assert.Len(t, processes, 2)
+	assert.Equal(t, uint32(7), processes[0].Pid)
+	assert.Equal(t, WatchedProcess{Pid: 8, Command: "trainer", CPUPercent: 90, MemoryPercent: 20, GPUPercent: 60, Seen: true}, *processes[1])
+
+	// An exited PID stays exited, even when a new process reuses it
+	w.Update(CPUMemoryMetrics{Processes: []CPUProcessInfo{{Pid: 7, Command: "redis"}}})
+	w.Update(CPUMemoryMetrics{Processes: []CPUProcessInfo{{Pid: 7, Command: "redis"}, {Pid: 8, Command: "trainer", CPUPercent: 50}}})
+	assert.True(t, processes[1].Exited)
+	assert.Equal(t, 0.0, processes[1].CPUPercent)
+	assert.False(t, processes[0].Exited)
+
+	assert.Empty(t, w.Empty().Processes()[0].Command)
+}
+
+func TestWatchListDropsExitedMatches(t *testing.T) {
+	w := NewWatchList([]uint32{1}, regexp.MustCompile(`^job`))
+	for i := range maxExitedMatches + 5 {
+		w.Update(CPUMemoryMetrics{Processes: []CPUProcessInfo{{Pid: 1, Command: "init"}, {Pid: uint32(100 + i), Command: "job"}}})
+	}
+	// The PID-watched process exits too, but keeps its row
+	w.Update(CPUMemoryMetrics{})
+
+	processes := w.Processes()
+	assert.Len(t, processes, maxExitedMatches+1)
+	assert.Equal(t, uint32(1), processes[0].Pid)
+	assert.True(t, processes[0].Exited)
+	// The oldest matches went first
+	assert.Equal(t, uint32(105), processes[1].Pid)
+	assert.Equal(t, uint32(100+maxExitedMatches+4), processes[len(processes)-1].Pid)
+}
@@ -0,0 +1,170 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+// CSVTimeFormat is the timestamp column format, ISO 8601 with milliseconds
+const CSVTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// CSVWriter writes metrics as CSV for spreadsheets and notebooks. The system
+// stream has one row per sample: timestamp, total CPU, one column per core,
+// memory, and utilization and memory per GPU. Its columns are fixed by the
+// first row, so later rows keep the same shape. The optional process stream
+// has one row per process and sample.
+type CSVWriter struct {
+	system    *csv.Writer
+	processes *csv.Writer
+	cores     int
+	gpus      int
+	started   bool
+	cpu       *domain.CPUMemoryMetrics
+	gpu       *domain.GPUMetrics
+}
+
+// NewCSVWriter writes system rows to system and, if processes is not nil,
+// process rows to processes
+func NewCSVWriter(system, processes io.Writer) *CSVWriter {
+	c := &CSVWriter{system: csv.NewWriter(system)}
+	if processes != nil {
+		c.processes = csv.NewWriter(processes)
+	}
+	return c
+}
+
+// Update records a collector value for the next row
+func (c *CSVWriter) Update(value any) {
+	switch msg := value.(type) {
+	case domain.CPUMemoryMetrics:
+		c.cpu = &msg
+	case domain.GPUMetrics:
+		c.gpu = &msg
+	}
+}
+
+// WriteRow writes the latest values. Nothing is written before the first
+// CPU sample.
+func (c *CSVWriter) WriteRow(at time.Time) error {
+	if c.cpu == nil {
+		return nil
+	}
+	timestamp := at.Format(CSVTimeFormat)
+	devices := csvDevices(c.gpu)
+	if !c.started {
+		c.cores = len(c.cpu.CPUUsagePerCore)
+		c.gpus = len(devices)
+		if err := c.writeHeaders(); err != nil {
+			return err
+		}
+		c.started = true
+	}
+
+	row := []string{timestamp, csvValue(c.cpu.CPUUsageTotal)}
+	for i := range c.cores {
+		row = append(row, csvIndex(c.cpu.CPUUsagePerCore, i))
+	}
+	row = append(row, csvValue(c.cpu.MemoryUsage))
+	for i := range c.gpus {
+		if i < len(devices) {
+			row = append(row, csvValue(devices[i].Usage), csvValue(devices[i].MemoryUsage))
+		} else {
+			row = append(row, "", "")
+		}
+	}
+	c.system.Write(row)
+	c.system.Flush()
+	if err := c.system.Error(); err != nil {
+		return err
+	}
+
+	if c.processes == nil {
+		return nil
+	}
+	for _, row := range c.processRows(timestamp) {
+		c.processes.Write(row)
+	}
+	c.processes.Flush()
+	return c.processes.Error()
+}
+
+func (c *CSVWriter) writeHeaders() error {
+	header := []string{"timestamp", "cpu_total"}
+	for i := range c.cores {
+		header = append(header, fmt.Sprintf("cpu%d", i))
+	}
+	header = append(header, "memory")
+	for i := range c.gpus {
+		header = append(header, fmt.Sprintf("gpu%d_util", i), fmt.Sprintf("gpu%d_mem", i))
+	}
+	if err := c.system.Write(header); err != nil {
+		return err
+	}
+	if c.processes != nil {
+		return c.processes.Write([]string{"timestamp", "pid", "user", "command", "cpu", "mem", "gpu"})
+	}
+	return nil
+}
+
+// processRows merges CPU and GPU processes by pid; the gpu column is empty
+// for processes without GPU activity
+func (c *CSVWriter) processRows(timestamp string) [][]string {
+	type process struct {
+		user, command string
+		cpu, mem, gpu string
+	}
+	byPID := make(map[uint32]*process)
+	for _, p := range c.cpu.Processes {
+		byPID[p.Pid] = &process{user: p.User, command: p.Command, cpu: csvValue(p.CPUPercent), mem: csvValue(p.MemoryPercent)}
+	}
+	if c.gpu != nil {
+		for _, p := range c.gpu.Processes {
+			if byPID[p.Pid] == nil {
+				byPID[p.Pid] = &process{user: p.User}
+			}
+			byPID[p.Pid].gpu = strconv.FormatUint(uint64(p.SmUtil), 10)
+		}
+	}
+
+	pids := make([]uint32, 0, len(byPID))
+	for pid := range byPID {
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+
+	rows := make([][]string, 0, len(pids))
+	for _, pid := range pids {
+		p := byPID[pid]
+		rows = append(rows, []string{timestamp, strconv.FormatUint(uint64(pid), 10), p.user, p.command, p.cpu, p.mem, p.gpu})
+	}
+	return rows
+}
+
+// csvDevices returns per-GPU metrics, or the aggregate as a single GPU
+func csvDevices(gpu *domain.GPUMetrics) []domain.GPUDeviceMetrics {
+	if gpu == nil {
+		return nil
+	}
+	if len(gpu.Devices) == 0 {
+		return []domain.GPUDeviceMetrics{{Usage: gpu.GPUUsage, MemoryUsage: gpu.GPUMemoryUsage}}
+	}
+	return gpu.Devices
+}
+
+func csvValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// csvIndex formats values[i], or an empty cell when it is missing
+func csvIndex(values []float64, i int) string {
+	if i >= len(values) {
+		return ""
+	}
+	return csvValue(values[i])
+}
@@ -0,0 +1,183 @@
+// Package export pushes collector metrics to external monitoring systems.
+//
+// An Exporter keeps the latest value of every collector and, on a fixed
+// interval, turns them into a Snapshot of gauge points that it writes to each
+// configured Sink.
+package export
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+const (
+	// DefaultInterval is how often snapshots are pushed unless configured
+	DefaultInterval = 10 * time.Second
+	// processPoints is how many of the busiest processes are exported
+	processPoints = 10
+)
+
+// Gauge names of the host-wide points
+const (
+	MetricCPU       = "mim.cpu.utilization"
+	MetricCPUCore   = "mim.cpu.core.utilization"
+	MetricMemory    = "mim.memory.utilization"
+	MetricGPU       = "mim.gpu.utilization"
+	MetricGPUMemory = "mim.gpu.memory.utilization"
+)
+
+// Point is one gauge reading
+type Point struct {
+	Name  string
+	Unit  string
+	Value float64
+	// Attributes distinguish points of the same gauge: cpu, gpu, pid, user
+	// and command
+	Attributes map[string]string
+	// Device is the UUID of the GPU the reading belongs to, if any
+	Device string
+}
+
+// Snapshot is every gauge of one host at one instant
+type Snapshot struct {
+	Time   time.Time
+	Host   string
+	Points []Point
+}
+
+// Sink writes snapshots to a monitoring system
+type Sink interface {
+	Export(ctx context.Context, snapshot Snapshot) error
+	Close() error
+}
+
+// Exporter collects the latest metrics and pushes them to its sinks
+type Exporter struct {
+	host     string
+	interval time.Duration
+	sinks    []Sink
+	mu       sync.Mutex
+	cpu      *domain.CPUMemoryMetrics
+	gpu      *domain.GPUMetrics
+}
+
+func NewExporter(host string, interval time.Duration, sinks ...Sink) *Exporter {
+	return &Exporter{host: host, interval: interval, sinks: sinks}
+}
+
+// Update records a collector value; values of unknown types are ignored
+func (e *Exporter) Update(value any) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch msg := value.(type) {
+	case domain.CPUMemoryMetrics:
+		e.cpu = &msg
+	case domain.GPUMetrics:
+		e.gpu = &msg
+	}
+}
+
+// Snapshot returns the gauges of the latest values
+func (e *Exporter) Snapshot(at time.Time) Snapshot {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	snapshot := Snapshot{Time: at, Host: e.host}
+	if e.cpu != nil {
+		snapshot.Points = append(snapshot.Points, cpuPoints(*e.cpu)...)
+	}
+	if e.gpu != nil {
+		snapshot.Points = append(snapshot.Points, gpuPoints(*e.gpu)...)
+	}
+	return snapshot
+}
+
+// Run pushes a snapshot every interval until stop is closed, then closes
+// the sinks. A failing sink is logged and retried on the next interval.
+func (e *Exporter) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	defer func() {
+		for _, sink := range e.sinks {
+			if err := sink.Close(); err != nil {
+				log.Printf("export: closing sink: %v", err)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			snapshot := e.Snapshot(now)
+			if len(snapshot.Points) == 0 {
+				continue
+			}
+			for _, sink := range e.sinks {
+				ctx, cancel := context.WithTimeout(context.Background(), e.interval)
+				if err := sink.Export(ctx, snapshot); err != nil {
+					log.Printf("export: %v", err)
+				}
+				cancel()
+			}
+		}
+	}
+}
+
+func cpuPoints(m domain.CPUMemoryMetrics) []Point {
+	points := []Point{
+		{Name: MetricCPU, Unit: "%", Value: m.CPUUsageTotal},
+		{Name: MetricMemory, Unit: "%", Value: m.MemoryUsage},
+	}
+	for i, usage := range m.CPUUsagePerCore {
+		points = append(points, Point{
+			Name:       MetricCPUCore,
+			Unit:       "%",
+			Value:      usage,
+			Attributes: map[string]string{"cpu": strconv.Itoa(i)},
+		})
+	}
+
+	processes := append([]domain.CPUProcessInfo(nil), m.Processes...)
+	sort.Slice(processes, func(i, j int) bool { return processes[i].CPUPercent > processes[j].CPUPercent })
+	for _, p := range processes[:min(processPoints, len(processes))] {
+		attributes := map[string]string{"pid": strconv.FormatUint(uint64(p.Pid), 10), "user": p.User, "command": p.Command}
+		points = append(points,
+			Point{Name: "mim.process.cpu.utilization", Unit: "%", Value: p.CPUPercent, Attributes: attributes},
+			Point{Name: "mim.process.memory.utilization", Unit: "%", Value: p.MemoryPercent, Attributes: attributes},
+		)
+	}
+	return points
+}
+
+func gpuPoints(m domain.GPUMetrics) []Point {
+	devices := m.Devices
+	if len(devices) == 0 {
+		devices = []domain.GPUDeviceMetrics{{Usage: m.GPUUsage, MemoryUsage: m.GPUMemoryUsage}}
+	}
+	var points []Point
+	for _, device := range devices {
+		attributes := map[string]string{"gpu": strconv.Itoa(device.Index)}
+		points = append(points,
+			Point{Name: MetricGPU, Unit: "%", Value: device.Usage, Attributes: attributes, Device: device.UUID},
+			Point{Name: MetricGPUMemory, Unit: "%", Value: device.MemoryUsage, Attributes: attributes, Device: device.UUID},
+		)
+	}
+
+	processes := append([]domain.GPUProcessInfo(nil), m.Processes...)
+	sort.Slice(processes, func(i, j int) bool { return processes[i].SmUtil > processes[j].SmUtil })
+	for _, p := range processes[:min(processPoints, len(processes))] {
+		attributes := map[string]string{"pid": strconv.FormatUint(uint64(p.Pid), 10), "user": p.User}
+		points = append(points,
+			Point{Name: "mim.process.gpu.utilization", Unit: "%", Value: float64(p.SmUtil), Attributes: attributes},
+			Point{Name: "mim.process.gpu.memory.utilization", Unit: "%", Value: p.UsedGpuMemory, Attributes: attributes},
+		)
+	}
+	return points
+}
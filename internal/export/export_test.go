@@ -0,0 +1,238 @@
+package export
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeReceiver is an in-process OTLP receiver for both transports
+type fakeReceiver struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+	requests chan *colmetricspb.ExportMetricsServiceRequest
+}
+
+func newFakeReceiver() *fakeReceiver {
+	return &fakeReceiver{requests: make(chan *colmetricspb.ExportMetricsServiceRequest, 16)}
+}
+
+func (r *fakeReceiver) Export(_ context.Context, req *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	r.requests <- req
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+func (r *fakeReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	var request colmetricspb.ExportMetricsServiceRequest
+	if req.URL.Path != otlpMetricsPath || proto.Unmarshal(body, &request) != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	r.requests <- &request
+	w.Header().Set("Content-Type", "application/x-protobuf")
+}
+
+func (r *fakeReceiver) receive(t *testing.T) *colmetricspb.ExportMetricsServiceRequest {
+	t.Helper()
+	select {
+	case req := <-r.requests:
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing exported")
+		return nil
+	}
+}
+
+// gauges flattens a request to "resource attribute/metric" => values
+func gauges(req *colmetricspb.ExportMetricsServiceRequest) map[string][]float64 {
+	values := make(map[string][]float64)
+	for _, rm := range req.ResourceMetrics {
+		device := ""
+		for _, kv := range rm.Resource.Attributes {
+			if kv.Key == "gpu.uuid" {
+				device = kv.Value.GetStringValue()
+			}
+		}
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				for _, dp := range m.GetGauge().DataPoints {
+					values[device+"/"+m.Name] = append(values[device+"/"+m.Name], dp.GetAsDouble())
+				}
+			}
+		}
+	}
+	return values
+}
+
+func TestOTLPHTTPExport(t *testing.T) {
+	receiver := newFakeReceiver()
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	sink, err := NewOTLPSink(OTLPHTTP, server.URL)
+	require.NoError(t, err)
+	exporter := NewExporter("box", 10*time.Millisecond, sink)
+	exporter.Update(domain.CPUMemoryMetrics{CPUUsageTotal: 25, MemoryUsage: 50, CPUUsagePerCore: []float64{20, 30}})
+	exporter.Update(domain.GPUMetrics{Devices: []domain.GPUDeviceMetrics{
+		{Index: 0, UUID: "GPU-a", Usage: 70, MemoryUsage: 10},
+		{Index: 1, UUID: "GPU-b", Usage: 90, MemoryUsage: 20},
+	}})
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		exporter.Run(stop)
+		close(done)
+	}()
+	req := receiver.receive(t)
+	close(stop)
+	<-done
+
+	hostAttributes := req.ResourceMetrics[0].Resource.Attributes
+	assert.Equal(t, "host.name", hostAttributes[0].Key)
+	assert.Equal(t, "box", hostAttributes[0].Value.GetStringValue())
+	assert.Equal(t, map[string][]float64{
+		"/mim.cpu.utilization":             {25},
+		"/mim.memory.utilization":          {50},
+		"/mim.cpu.core.utilization":        {20, 30},
+		"GPU-a/mim.gpu.utilization":        {70},
+		"GPU-a/mim.gpu.memory.utilization": {10},
+		"GPU-b/mim.gpu.utilization":        {90},
+		"GPU-b/mim.gpu.memory.utilization": {20},
+	}, gauges(req))
+}
+
+func TestOTLPGRPCExport(t *testing.T) {
+	receiver := newFakeReceiver()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	colmetricspb.RegisterMetricsServiceServer(server, receiver)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	sink, err := NewOTLPSink(OTLPGRPC, listener.Addr().String())
+	require.NoError(t, err)
+	defer sink.Close()
+
+	exporter := NewExporter("box", time.Second)
+	exporter.Update(domain.CPUMemoryMetrics{CPUUsageTotal: 5})
+	require.NoError(t, sink.Export(context.Background(), exporter.Snapshot(time.Now())))
+	assert.Equal(t, []float64{5}, gauges(receiver.receive(t))["/mim.cpu.utilization"])
+}
+
+func TestOTLPSinkErrors(t *testing.T) {
+	_, err := NewOTLPSink("carrier-pigeon", "localhost:4318")
+	assert.Error(t, err)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	sink, err := NewOTLPSink(OTLPHTTP, server.URL)
+	require.NoError(t, err)
+	err = sink.Export(context.Background(), Snapshot{Time: time.Now(), Points: []Point{{Name: "x"}}})
+	assert.ErrorContains(t, err, "404")
+}
+
+func testSnapshot() Snapshot {
+	exporter := NewExporter("box", time.Second)
+	exporter.Update(domain.CPUMemoryMetrics{
+		CPUUsagePerCore: []float64{12.5},
+		Processes:       []domain.CPUProcessInfo{{Pid: 42, CPUPercent: 80, User: "ann", Command: "make all"}},
+	})
+	exporter.Update(domain.GPUMetrics{Devices: []domain.GPUDeviceMetrics{{Index: 0, UUID: "GPU-a", Usage: 70}}})
+	return exporter.Snapshot(time.Unix(1700000000, 0))
+}
+
+func TestInfluxSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.lp")
+	sink, err := NewInfluxSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Export(context.Background(), testSnapshot()))
+	require.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := string(data)
+	assert.Contains(t, lines, "mim.cpu.core.utilization,cpu=0,host=box value=12.5 1700000000000000000\n")
+	assert.Contains(t, lines, `mim.process.cpu.utilization,command=make\ all,host=box,pid=42,user=ann value=80 1700000000000000000`)
+	assert.Contains(t, lines, "mim.gpu.utilization,gpu=0,gpu_uuid=GPU-a,host=box value=70 ")
+}
+
+func TestStatsDSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	read := func() string {
+		var received []string
+		buf := make([]byte, maxDatagram)
+		conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return strings.Join(received, "\n")
+			}
+			assert.LessOrEqual(t, n, maxDatagram)
+			received = append(received, string(buf[:n]))
+		}
+	}
+
+	sink, err := NewStatsDSink(conn.LocalAddr().String(), false)
+	require.NoError(t, err)
+	require.NoError(t, sink.Export(context.Background(), testSnapshot()))
+	sink.Close()
+	plain := read()
+	assert.Contains(t, plain, "mim.cpu.core.utilization.cpu_0:12.5|g")
+	assert.NotContains(t, plain, "mim.process")
+
+	sink, err = NewStatsDSink(conn.LocalAddr().String(), true)
+	require.NoError(t, err)
+	require.NoError(t, sink.Export(context.Background(), testSnapshot()))
+	sink.Close()
+	dog := read()
+	assert.Contains(t, dog, "mim.process.cpu.utilization:80|g|#command:make all,host:box,pid:42,user:ann")
+	assert.Contains(t, dog, "mim.gpu.utilization:70|g|#gpu:0,gpu_uuid:GPU-a,host:box")
+}
+
+func TestCSVWriter(t *testing.T) {
+	var system, processes strings.Builder
+	writer := NewCSVWriter(&system, &processes)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, writer.WriteRow(start)) // nothing before the first CPU sample
+	writer.Update(domain.GPUMetrics{
+		Devices:   []domain.GPUDeviceMetrics{{Index: 0, Usage: 70, MemoryUsage: 10}, {Index: 1, Usage: 90, MemoryUsage: 20}},
+		Processes: []domain.GPUProcessInfo{{Pid: 42, SmUtil: 55}, {Pid: 7, SmUtil: 5, User: "bob"}},
+	})
+	writer.Update(domain.CPUMemoryMetrics{
+		CPUUsageTotal: 25, CPUUsagePerCore: []float64{20, 30}, MemoryUsage: 50,
+		Processes: []domain.CPUProcessInfo{{Pid: 42, CPUPercent: 80, MemoryPercent: 1.5, User: "ann", Command: "make, all"}},
+	})
+	require.NoError(t, writer.WriteRow(start))
+
+	// A core and a GPU going missing keeps the columns
+	writer.Update(domain.CPUMemoryMetrics{CPUUsageTotal: 10, CPUUsagePerCore: []float64{10}, MemoryUsage: 40})
+	writer.Update(domain.GPUMetrics{Devices: []domain.GPUDeviceMetrics{{Index: 0, Usage: 1, MemoryUsage: 2}}})
+	require.NoError(t, writer.WriteRow(start.Add(time.Second)))
+
+	assert.Equal(t, "timestamp,cpu_total,cpu0,cpu1,memory,gpu0_util,gpu0_mem,gpu1_util,gpu1_mem\n"+
+		"2024-05-01T12:00:00.000Z,25.00,20.00,30.00,50.00,70.00,10.00,90.00,20.00\n"+
+		"2024-05-01T12:00:01.000Z,10.00,10.00,,40.00,1.00,2.00,,\n", system.String())
+	assert.Equal(t, "timestamp,pid,user,command,cpu,mem,gpu\n"+
+		"2024-05-01T12:00:00.000Z,7,bob,,,,5\n"+
+		"2024-05-01T12:00:00.000Z,42,ann,\"make, all\",80.00,1.50,55\n", processes.String())
+}
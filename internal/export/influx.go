@@ -0,0 +1,48 @@
+package export
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// InfluxSink writes snapshots as InfluxDB line protocol. The measurement is
+// the gauge name, the field is "value", and host, GPU UUID and point
+// attributes become tags.
+type InfluxSink struct {
+	out *lineWriter
+}
+
+// NewInfluxSink writes to stdout for "-", to udp://host:port, or appends to
+// a file
+func NewInfluxSink(target string) (*InfluxSink, error) {
+	out, err := openLines(target)
+	if err != nil {
+		return nil, err
+	}
+	return &InfluxSink{out: out}, nil
+}
+
+func (s *InfluxSink) Export(_ context.Context, snapshot Snapshot) error {
+	timestamp := strconv.FormatInt(snapshot.Time.UnixNano(), 10)
+	lines := make([]string, 0, len(snapshot.Points))
+	for _, point := range snapshot.Points {
+		var line strings.Builder
+		line.WriteString(influxEscaper.Replace(point.Name))
+		for _, tag := range tags(snapshot, point) {
+			line.WriteString("," + influxTagEscaper.Replace(tag[0]) + "=" + influxTagEscaper.Replace(tag[1]))
+		}
+		line.WriteString(" value=" + formatValue(point.Value) + " " + timestamp)
+		lines = append(lines, line.String())
+	}
+	return s.out.write(lines)
+}
+
+func (s *InfluxSink) Close() error {
+	return s.out.Close()
+}
+
+var (
+	influxEscaper    = strings.NewReplacer(",", `\,`, " ", `\ `)
+	influxTagEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+)
@@ -0,0 +1,105 @@
+package export
+
+import (
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxDatagram keeps UDP packets below a typical MTU so they are not
+// fragmented
+const maxDatagram = 1400
+
+// lineWriter writes text lines to a stream, or packs them into datagrams
+type lineWriter struct {
+	w      io.Writer
+	closer io.Closer
+	packet bool // w is a UDP connection
+}
+
+// openLines opens "-" for stdout, udp://host:port, or a file to append to
+func openLines(target string) (*lineWriter, error) {
+	if target == "-" {
+		return &lineWriter{w: os.Stdout}, nil
+	}
+	if address, ok := strings.CutPrefix(target, "udp://"); ok {
+		return dialLines(address)
+	}
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &lineWriter{w: file, closer: file}, nil
+}
+
+// dialLines sends lines as UDP datagrams to address
+func dialLines(address string) (*lineWriter, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &lineWriter{w: conn, closer: conn, packet: true}, nil
+}
+
+// write sends the lines, as few datagrams as fit when writing to UDP
+func (l *lineWriter) write(lines []string) error {
+	if !l.packet {
+		_, err := io.WriteString(l.w, strings.Join(lines, "\n")+"\n")
+		return err
+	}
+
+	var packet strings.Builder
+	flush := func() error {
+		if packet.Len() == 0 {
+			return nil
+		}
+		_, err := io.WriteString(l.w, packet.String())
+		packet.Reset()
+		return err
+	}
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > maxDatagram {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	return flush()
+}
+
+func (l *lineWriter) Close() error {
+	if l.closer != nil {
+		return l.closer.Close()
+	}
+	return nil
+}
+
+// tags returns a point's attributes plus its host and GPU UUID, sorted by
+// key and without empty values
+func tags(snapshot Snapshot, point Point) [][2]string {
+	all := map[string]string{"host": snapshot.Host, "gpu_uuid": point.Device}
+	for k, v := range point.Attributes {
+		all[k] = v
+	}
+	var kvs [][2]string
+	for k, v := range all {
+		if v != "" {
+			kvs = append(kvs, [2]string{k, v})
+		}
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i][0] < kvs[j][0] })
+	return kvs
+}
+
+// formatValue prints a gauge in plain decimal notation, which every
+// protocol here accepts
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
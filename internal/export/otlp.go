@@ -0,0 +1,178 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+)
+
+// OTLP transports
+const (
+	OTLPHTTP = "http"
+	OTLPGRPC = "grpc"
+)
+
+// otlpMetricsPath is where OTLP/HTTP receivers accept metrics
+const otlpMetricsPath = "/v1/metrics"
+
+// OTLPSink pushes snapshots as OpenTelemetry gauges over OTLP/HTTP or
+// OTLP/gRPC. Each host and each GPU is its own resource, so the host name
+// and GPU UUID are resource attributes.
+type OTLPSink struct {
+	url    string // OTLP/HTTP
+	client *http.Client
+	conn   *grpc.ClientConn // OTLP/gRPC
+	grpc   colmetricspb.MetricsServiceClient
+}
+
+// NewOTLPSink connects to a receiver. For HTTP the endpoint is a URL, to
+// which /v1/metrics is added when it has no path; for gRPC it is host:port,
+// optionally prefixed with https:// to use TLS.
+func NewOTLPSink(protocol, endpoint string) (*OTLPSink, error) {
+	switch protocol {
+	case OTLPHTTP:
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTLP endpoint: %w", err)
+		}
+		if u.Path == "" || u.Path == "/" {
+			u.Path = otlpMetricsPath
+		}
+		return &OTLPSink{url: u.String(), client: &http.Client{}}, nil
+
+	case OTLPGRPC:
+		creds := insecure.NewCredentials()
+		if target, ok := strings.CutPrefix(endpoint, "https://"); ok {
+			endpoint = target
+			creds = credentials.NewTLS(&tls.Config{})
+		}
+		endpoint = strings.TrimPrefix(endpoint, "http://")
+		conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTLP endpoint: %w", err)
+		}
+		return &OTLPSink{conn: conn, grpc: colmetricspb.NewMetricsServiceClient(conn)}, nil
+	}
+	return nil, fmt.Errorf("unknown OTLP protocol %q (want %s or %s)", protocol, OTLPHTTP, OTLPGRPC)
+}
+
+func (s *OTLPSink) Export(ctx context.Context, snapshot Snapshot) error {
+	request := otlpRequest(snapshot)
+	if s.grpc != nil {
+		if _, err := s.grpc.Export(ctx, request); err != nil {
+			return fmt.Errorf("OTLP push failed: %w", err)
+		}
+		return nil
+	}
+
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("OTLP push failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("OTLP push failed: %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *OTLPSink) Close() error {
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+// otlpRequest groups the points by resource, the host or one of its GPUs,
+// and by gauge name
+func otlpRequest(snapshot Snapshot) *colmetricspb.ExportMetricsServiceRequest {
+	timestamp := uint64(snapshot.Time.UnixNano())
+	resources := make(map[string]*metricspb.ResourceMetrics)
+	metrics := make(map[[2]string]*metricspb.Metric)
+	var order []string
+
+	for _, point := range snapshot.Points {
+		rm, ok := resources[point.Device]
+		if !ok {
+			resource := map[string]string{"service.name": "mim", "host.name": snapshot.Host}
+			if point.Device != "" {
+				resource["gpu.uuid"] = point.Device
+			}
+			rm = &metricspb.ResourceMetrics{
+				Resource:     &resourcepb.Resource{Attributes: otlpAttributes(resource)},
+				ScopeMetrics: []*metricspb.ScopeMetrics{{Scope: &commonpb.InstrumentationScope{Name: "mim"}}},
+			}
+			resources[point.Device] = rm
+			order = append(order, point.Device)
+		}
+
+		metric, ok := metrics[[2]string{point.Device, point.Name}]
+		if !ok {
+			metric = &metricspb.Metric{
+				Name: point.Name,
+				Unit: point.Unit,
+				Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{}},
+			}
+			metrics[[2]string{point.Device, point.Name}] = metric
+			rm.ScopeMetrics[0].Metrics = append(rm.ScopeMetrics[0].Metrics, metric)
+		}
+		gauge := metric.GetGauge()
+		gauge.DataPoints = append(gauge.DataPoints, &metricspb.NumberDataPoint{
+			Attributes:   otlpAttributes(point.Attributes),
+			TimeUnixNano: timestamp,
+			Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: point.Value},
+		})
+	}
+
+	request := &colmetricspb.ExportMetricsServiceRequest{}
+	for _, device := range order {
+		request.ResourceMetrics = append(request.ResourceMetrics, resources[device])
+	}
+	return request
+}
+
+// otlpAttributes converts attributes in a stable order
+func otlpAttributes(attributes map[string]string) []*commonpb.KeyValue {
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]*commonpb.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, &commonpb.KeyValue{
+			Key:   k,
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: attributes[k]}},
+		})
+	}
+	return kvs
+}
@@ -0,0 +1,60 @@
+package export
+
+import (
+	"context"
+	"strings"
+)
+
+// StatsDSink sends snapshots as StatsD gauges over UDP. Plain StatsD has no
+// tags, so point attributes are appended to the gauge name; DogStatsD sends
+// them, with the host and GPU UUID, as tags. Per-process points are only
+// sent to DogStatsD: in a name, every PID would make a new metric.
+type StatsDSink struct {
+	out       *lineWriter
+	dogstatsd bool
+}
+
+func NewStatsDSink(address string, dogstatsd bool) (*StatsDSink, error) {
+	out, err := dialLines(address)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsDSink{out: out, dogstatsd: dogstatsd}, nil
+}
+
+func (s *StatsDSink) Export(_ context.Context, snapshot Snapshot) error {
+	lines := make([]string, 0, len(snapshot.Points))
+	for _, point := range snapshot.Points {
+		if _, ok := point.Attributes["pid"]; ok && !s.dogstatsd {
+			continue
+		}
+		name := point.Name
+		var suffix string
+		if s.dogstatsd {
+			var kvs []string
+			for _, tag := range tags(snapshot, point) {
+				kvs = append(kvs, statsdEscaper.Replace(tag[0])+":"+statsdEscaper.Replace(tag[1]))
+			}
+			if len(kvs) > 0 {
+				suffix = "|#" + strings.Join(kvs, ",")
+			}
+		} else {
+			for _, tag := range tags(Snapshot{}, point) {
+				name += "." + statsdNameEscaper.Replace(tag[0]) + "_" + statsdNameEscaper.Replace(tag[1])
+			}
+		}
+		lines = append(lines, name+":"+formatValue(point.Value)+"|g"+suffix)
+	}
+	return s.out.write(lines)
+}
+
+func (s *StatsDSink) Close() error {
+	return s.out.Close()
+}
+
+var (
+	// statsdEscaper removes the separators of the DogStatsD tag list
+	statsdEscaper = strings.NewReplacer(",", "_", "|", "_", "#", "_", "\n", "_")
+	// statsdNameEscaper keeps attribute values from splitting the name
+	statsdNameEscaper = strings.NewReplacer(".", "_", ":", "_", "|", "_", " ", "_", "@", "_", "\n", "_")
+)
@@ -0,0 +1,73 @@
+package infra
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+// CPUFreqReader reads per-core frequency, governor and limits from the
+// cpufreq sysfs interface
+type CPUFreqReader struct {
+	sysRoot string
+}
+
+// NewCPUFreqReader creates a reader rooted at sysRoot, normally "/sys"
+func NewCPUFreqReader(sysRoot string) *CPUFreqReader {
+	return &CPUFreqReader{sysRoot: sysRoot}
+}
+
+// Read returns frequency information for every CPU exposing cpufreq, sorted
+// by logical CPU. Machines without cpufreq (many VMs) return an empty slice.
+func (r *CPUFreqReader) Read() ([]domain.CoreFrequency, error) {
+	freqDirs, err := filepath.Glob(filepath.Join(r.sysRoot, "devices/system/cpu/cpu[0-9]*/cpufreq"))
+	if err != nil {
+		return nil, err
+	}
+
+	frequencies := make([]domain.CoreFrequency, 0, len(freqDirs))
+	for _, dir := range freqDirs {
+		cpu, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(filepath.Dir(dir)), "cpu"))
+		if err != nil {
+			continue
+		}
+
+		current := readKHzAsMHz(filepath.Join(dir, "scaling_cur_freq"))
+		if current == 0 {
+			// Some drivers only expose the hardware-reported frequency
+			current = readKHzAsMHz(filepath.Join(dir, "cpuinfo_cur_freq"))
+		}
+
+		governor, _ := os.ReadFile(filepath.Join(dir, "scaling_governor"))
+		frequencies = append(frequencies, domain.CoreFrequency{
+			CPU:         cpu,
+			CurrentMHz:  current,
+			MinMHz:      readKHzAsMHz(filepath.Join(dir, "scaling_min_freq")),
+			MaxMHz:      readKHzAsMHz(filepath.Join(dir, "scaling_max_freq")),
+			HardwareMax: readKHzAsMHz(filepath.Join(dir, "cpuinfo_max_freq")),
+			Governor:    strings.TrimSpace(string(governor)),
+		})
+	}
+
+	sort.Slice(frequencies, func(i, j int) bool {
+		return frequencies[i].CPU < frequencies[j].CPU
+	})
+	return frequencies, nil
+}
+
+// readKHzAsMHz reads a cpufreq kHz value, returning 0 when unavailable
+func readKHzAsMHz(path string) float64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	khz, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	if err != nil {
+		return 0
+	}
+	return khz / 1000
+}
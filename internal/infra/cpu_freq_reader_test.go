@@ -0,0 +1,48 @@
+package infra
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSysfsFile(t *testing.T, root, path, content string) {
+	t.Helper()
+	full := filepath.Join(root, path)
+	require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+	require.NoError(t, os.WriteFile(full, []byte(content), 0o644))
+}
+
+func TestCPUFreqReaderRead(t *testing.T) {
+	root := t.TempDir()
+	for _, cpu := range []string{"cpu0", "cpu10"} {
+		dir := "devices/system/cpu/" + cpu + "/cpufreq/"
+		writeSysfsFile(t, root, dir+"scaling_min_freq", "800000\n")
+		writeSysfsFile(t, root, dir+"scaling_max_freq", "3600000\n")
+		writeSysfsFile(t, root, dir+"cpuinfo_max_freq", "4800000\n")
+		writeSysfsFile(t, root, dir+"scaling_governor", "powersave\n")
+	}
+	writeSysfsFile(t, root, "devices/system/cpu/cpu0/cpufreq/scaling_cur_freq", "1200000\n")
+	writeSysfsFile(t, root, "devices/system/cpu/cpu10/cpufreq/cpuinfo_cur_freq", "3500000\n")
+	// A CPU without cpufreq is skipped
+	writeSysfsFile(t, root, "devices/system/cpu/cpu1/topology/core_id", "1\n")
+
+	frequencies, err := NewCPUFreqReader(root).Read()
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.CoreFrequency{
+		{CPU: 0, CurrentMHz: 1200, MinMHz: 800, MaxMHz: 3600, HardwareMax: 4800, Governor: "powersave"},
+		{CPU: 10, CurrentMHz: 3500, MinMHz: 800, MaxMHz: 3600, HardwareMax: 4800, Governor: "powersave"},
+	}, frequencies)
+}
+
+func TestCPUFreqReaderReadWithoutCPUFreq(t *testing.T) {
+	frequencies, err := NewCPUFreqReader(t.TempDir()).Read()
+
+	require.NoError(t, err)
+	assert.Empty(t, frequencies)
+}
@@ -1,6 +1,8 @@
 package infra
 
 import (
+	"fmt"
+	"log"
 	"time"
 
 	"github.com/jonsampson/mim/internal/domain"
@@ -12,23 +14,43 @@ import (
 type CPUMemoryCollector struct {
 	*BaseCollector[domain.CPUMemoryMetrics]
 	lastProcessTimes map[int32]*cpu.TimesStat
+	lastProcessIO    map[int32]domain.IOCounters
 	lastCollectTime  time.Time
+	sysRoot          string
+	topology         []domain.CoreTopology
+	freqReader       *CPUFreqReader
+	lastCoreTimes    []domain.SystemCPUTimes
+	lastTotalTimes   domain.SystemCPUTimes
+	lastMemCounters  domain.MemoryCounters
+	lastMemTime      time.Time
 	cpuCalculator    *domain.CPUCalculator
+	ioCalculator     *domain.IOCalculator
+	memCalculator    *domain.MemoryCalculator
 	processFilter    *domain.ProcessFilter
 	usernameCache    *UsernameCache
-	// Pre-allocated buffers to reduce GC pressure
-	processInfoBuffer []domain.CPUProcessInfo
 }
 
 func NewCPUMemoryCollector() *CPUMemoryCollector {
 	collector := &CPUMemoryCollector{
-		lastProcessTimes:  make(map[int32]*cpu.TimesStat),
-		lastCollectTime:   time.Now(),
-		cpuCalculator:     domain.NewCPUCalculator(),
-		processFilter:     domain.NewProcessFilter(),
-		usernameCache:     NewUsernameCache(),
-		processInfoBuffer: make([]domain.CPUProcessInfo, 0, 1000), // Pre-allocate for ~1000 processes
+		lastProcessTimes: make(map[int32]*cpu.TimesStat),
+		lastProcessIO:    make(map[int32]domain.IOCounters),
+		lastCollectTime:  time.Now(),
+		cpuCalculator:    domain.NewCPUCalculator(),
+		ioCalculator:     domain.NewIOCalculator(),
+		memCalculator:    domain.NewMemoryCalculator(),
+		processFilter:    domain.NewProcessFilter(),
+		usernameCache:    NewUsernameCache(),
+		sysRoot:          defaultSysRoot,
 	}
+
+	// Topology is static for the lifetime of the process
+	topology, err := readCPUTopology(collector.sysRoot)
+	if err != nil {
+		log.Printf("CPU topology unavailable, using logical order: %v", err)
+	}
+	collector.topology = topology
+	collector.freqReader = NewCPUFreqReader(collector.sysRoot)
+
 	collector.BaseCollector = NewBaseCollector(collector.getMetrics)
 	return collector
 }
@@ -38,9 +60,20 @@ func (c *CPUMemoryCollector) getMetrics() (domain.CPUMemoryMetrics, error) {
 		value any
 		err   error
 	}
+	type memoryResult struct {
+		usedPercent float64
+		breakdown   domain.MemoryBreakdown
+		numa        []domain.NUMANodeMemory
+	}
+	type timesResult struct {
+		perCore []domain.CPUTimeBreakdown
+		total   domain.CPUTimeBreakdown
+	}
 
 	perCoreChan := make(chan result)
 	totalChan := make(chan result)
+	timesChan := make(chan result)
+	freqChan := make(chan result)
 	memChan := make(chan result)
 	processesChan := make(chan result)
 
@@ -60,14 +93,64 @@ func (c *CPUMemoryCollector) getMetrics() (domain.CPUMemoryMetrics, error) {
 		}
 	}()
 
+	// Collect CPU time breakdowns from cumulative counters
+	go func() {
+		perCoreTimes, err := cpu.Times(true)
+		if err != nil {
+			timesChan <- result{nil, err}
+			return
+		}
+		totalTimes, err := cpu.Times(false)
+		if err != nil {
+			timesChan <- result{nil, err}
+			return
+		}
+		if len(totalTimes) == 0 {
+			timesChan <- result{nil, fmt.Errorf("no aggregate CPU times reported")}
+			return
+		}
+
+		currentCoreTimes := make([]domain.SystemCPUTimes, len(perCoreTimes))
+		for i, t := range perCoreTimes {
+			currentCoreTimes[i] = toSystemCPUTimes(t)
+		}
+		currentTotalTimes := toSystemCPUTimes(totalTimes[0])
+
+		times := timesResult{
+			perCore: c.cpuCalculator.CalculateTimeBreakdowns(currentCoreTimes, c.lastCoreTimes),
+			total:   c.cpuCalculator.CalculateTimeBreakdown(currentTotalTimes, c.lastTotalTimes),
+		}
+		c.lastCoreTimes = currentCoreTimes
+		c.lastTotalTimes = currentTotalTimes
+		timesChan <- result{times, nil}
+	}()
+
+	// Collect per-core frequencies; missing cpufreq is not an error
+	go func() {
+		frequencies, err := c.freqReader.Read()
+		if err != nil {
+			log.Printf("CPU frequency unavailable: %v", err)
+		}
+		freqChan <- result{frequencies, nil}
+	}()
+
 	// Get memory stats
 	go func() {
 		memStat, err := mem.VirtualMemory()
 		if err != nil {
 			memChan <- result{nil, err}
-		} else {
-			memChan <- result{memStat.UsedPercent, nil}
+			return
+		}
+		swapStat, err := mem.SwapMemory()
+		if err != nil {
+			memChan <- result{nil, err}
+			return
+		}
+		numa, err := readNUMAMemory(c.sysRoot)
+		if err != nil {
+			log.Printf("NUMA memory unavailable: %v", err)
 		}
+		memChan <- result{memoryResult{memStat.UsedPercent, c.memoryBreakdown(memStat, swapStat), numa}, nil}
 	}()
 
 	// Get process information
@@ -82,9 +165,11 @@ func (c *CPUMemoryCollector) getMetrics() (domain.CPUMemoryMetrics, error) {
 			return
 		}
 		
-		// Reuse pre-allocated buffer to reduce GC pressure
-		c.processInfoBuffer = c.processInfoBuffer[:0] // Reset length but keep capacity
+		// Each sample gets its own list: consumers such as the history keep
+		// samples while the next one is collected
+		processInfo := make([]domain.CPUProcessInfo, 0, len(processes))
 		newProcessTimes := make(map[int32]*cpu.TimesStat)
+		newProcessIO := make(map[int32]domain.IOCounters)
 		
 		for _, proc := range processes {
 			pid := proc.Pid
@@ -125,30 +210,54 @@ func (c *CPUMemoryCollector) getMetrics() (domain.CPUMemoryMetrics, error) {
 				continue
 			}
 			
+			// Get disk I/O rates; /proc/[pid]/io is unreadable for other
+			// users' processes without privileges, so keep those as unknown
+			var ioKnown bool
+			var readPerSec, writePerSec float64
+			if ioStat, err := proc.IOCounters(); err == nil {
+				currentIO := domain.IOCounters{
+					ReadBytes:  ioStat.DiskReadBytes,
+					WriteBytes: ioStat.DiskWriteBytes,
+				}
+				newProcessIO[pid] = currentIO
+				ioKnown = true
+				if lastIO, exists := c.lastProcessIO[pid]; exists {
+					readPerSec, writePerSec = c.ioCalculator.CalculateIORates(currentIO, lastIO, deltaTime)
+				}
+			}
+
 			// Get username using cache (fast after first few lookups due to UID deduplication)
 			username := c.usernameCache.GetUsername(uint32(pid))
+
+			// The parent lets a process tree be followed, as by mim run
+			ppid, _ := proc.Ppid()
 			
-			c.processInfoBuffer = append(c.processInfoBuffer, domain.CPUProcessInfo{
-				Pid:           uint32(pid),
-				CPUPercent:    cpuPercent,
-				MemoryPercent: float64(memPercent),
-				Command:       name,
-				User:          username,
+			processInfo = append(processInfo, domain.CPUProcessInfo{
+				Pid:              uint32(pid),
+				PPid:             uint32(ppid),
+				CPUPercent:       cpuPercent,
+				MemoryPercent:    float64(memPercent),
+				Command:          name,
+				User:             username,
+				IOKnown:          ioKnown,
+				ReadBytesPerSec:  readPerSec,
+				WriteBytesPerSec: writePerSec,
 			})
 		}
 		
 		// Update stored times and timestamp
 		c.lastProcessTimes = newProcessTimes
+		c.lastProcessIO = newProcessIO
 		c.lastCollectTime = currentTime
 		
-		processesChan <- result{c.processInfoBuffer, nil}
+		processesChan <- result{processInfo, nil}
 	}()
 
 	// Collect results and handle potential errors
 	metrics := domain.CPUMemoryMetrics{}
 	var err error
 
-	for range 4 {
+	for range 6 {
 		select {
 		case r := <-perCoreChan:
 			if r.err != nil {
@@ -160,11 +269,23 @@ func (c *CPUMemoryCollector) getMetrics() (domain.CPUMemoryMetrics, error) {
 				return domain.CPUMemoryMetrics{}, r.err
 			}
 			metrics.CPUUsageTotal = r.value.(float64)
+		case r := <-timesChan:
+			if r.err != nil {
+				return domain.CPUMemoryMetrics{}, r.err
+			}
+			times := r.value.(timesResult)
+			metrics.CPUTimesPerCore = times.perCore
+			metrics.CPUTimesTotal = times.total
+		case r := <-freqChan:
+			metrics.CoreFrequencies = r.value.([]domain.CoreFrequency)
 		case r := <-memChan:
 			if r.err != nil {
 				return domain.CPUMemoryMetrics{}, r.err
 			}
-			metrics.MemoryUsage = r.value.(float64)
+			memResult := r.value.(memoryResult)
+			metrics.MemoryUsage = memResult.usedPercent
+			metrics.Memory = memResult.breakdown
+			metrics.NUMAMemory = memResult.numa
 		case r := <-processesChan:
 			if r.err != nil {
 				return domain.CPUMemoryMetrics{}, r.err
@@ -173,6 +294,56 @@ func (c *CPUMemoryCollector) getMetrics() (domain.CPUMemoryMetrics, error) {
 		}
 	}
 
+	metrics.Topology = c.topology
+
 	// Return the collected metrics
 	return metrics, err
 }
+
+func toSystemCPUTimes(t cpu.TimesStat) domain.SystemCPUTimes {
+	return domain.SystemCPUTimes{
+		User:      t.User,
+		Nice:      t.Nice,
+		System:    t.System,
+		Idle:      t.Idle,
+		Iowait:    t.Iowait,
+		Irq:       t.Irq,
+		Softirq:   t.Softirq,
+		Steal:     t.Steal,
+		Guest:     t.Guest,
+		GuestNice: t.GuestNice,
+	}
+}
+
+// memoryBreakdown converts gopsutil memory stats into a domain breakdown,
+// computing swap rates against the previous collection.
+func (c *CPUMemoryCollector) memoryBreakdown(memStat *mem.VirtualMemoryStat, swapStat *mem.SwapMemoryStat) domain.MemoryBreakdown {
+	currentTime := time.Now()
+	counters := domain.MemoryCounters{
+		Total:         memStat.Total,
+		Free:          memStat.Free,
+		Used:          memStat.Used,
+		Buffers:       memStat.Buffers,
+		Cached:        memStat.Cached - memStat.Sreclaimable, // gopsutil folds reclaimable slab into Cached
+		Shared:        memStat.Shared,
+		SlabReclaim:   memStat.Sreclaimable,
+		SlabUnreclaim: memStat.Sunreclaim,
+		PageTables:    memStat.PageTables,
+		Dirty:         memStat.Dirty,
+		Writeback:     memStat.WriteBack,
+		SwapTotal:     swapStat.Total,
+		SwapFree:      swapStat.Free,
+		SwapInBytes:   swapStat.Sin,
+		SwapOutBytes:  swapStat.Sout,
+	}
+
+	var deltaTime float64
+	if !c.lastMemTime.IsZero() {
+		deltaTime = currentTime.Sub(c.lastMemTime).Seconds()
+	}
+	breakdown := c.memCalculator.CalculateBreakdown(counters, c.lastMemCounters, deltaTime)
+
+	c.lastMemCounters = counters
+	c.lastMemTime = currentTime
+	return breakdown
+}
@@ -0,0 +1,172 @@
+package infra
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+const defaultSysRoot = "/sys"
+
+// readCPUTopology reads socket, core and NUMA node placement for every
+// logical CPU from sysfs. It returns nil when sysfs topology is unavailable.
+func readCPUTopology(sysRoot string) ([]domain.CoreTopology, error) {
+	cpuDirs, err := filepath.Glob(filepath.Join(sysRoot, "devices/system/cpu/cpu[0-9]*"))
+	if err != nil {
+		return nil, err
+	}
+
+	nodeOfCPU, err := readCPUNodes(sysRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var topology []domain.CoreTopology
+	for _, dir := range cpuDirs {
+		cpu, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "cpu"))
+		if err != nil {
+			continue
+		}
+		socket, err := readIntFile(filepath.Join(dir, "topology/physical_package_id"))
+		if err != nil {
+			continue // offline CPUs have no topology directory
+		}
+		core, err := readIntFile(filepath.Join(dir, "topology/core_id"))
+		if err != nil {
+			continue
+		}
+		topology = append(topology, domain.CoreTopology{
+			CPU:    cpu,
+			Socket: socket,
+			Node:   nodeOfCPU[cpu],
+			Core:   core,
+		})
+	}
+	return topology, nil
+}
+
+// readCPUNodes maps logical CPUs to NUMA nodes. CPUs on machines without
+// NUMA information are reported as node 0.
+func readCPUNodes(sysRoot string) (map[int]int, error) {
+	nodeDirs, err := filepath.Glob(filepath.Join(sysRoot, "devices/system/node/node[0-9]*"))
+	if err != nil {
+		return nil, err
+	}
+
+	nodeOfCPU := make(map[int]int)
+	for _, dir := range nodeDirs {
+		node, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "node"))
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "cpulist"))
+		if err != nil {
+			continue
+		}
+		cpus, err := parseCPUList(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cpulist for node %d: %w", node, err)
+		}
+		for _, cpu := range cpus {
+			nodeOfCPU[cpu] = node
+		}
+	}
+	return nodeOfCPU, nil
+}
+
+// readNUMAMemory reads per-node memory totals from sysfs
+func readNUMAMemory(sysRoot string) ([]domain.NUMANodeMemory, error) {
+	nodeDirs, err := filepath.Glob(filepath.Join(sysRoot, "devices/system/node/node[0-9]*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []domain.NUMANodeMemory
+	for _, dir := range nodeDirs {
+		node, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "node"))
+		if err != nil {
+			continue
+		}
+		usage, err := readNodeMeminfo(filepath.Join(dir, "meminfo"))
+		if err != nil {
+			return nil, err
+		}
+		usage.Node = node
+		nodes = append(nodes, usage)
+	}
+	// Glob sorts node10 before node2
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Node < nodes[j].Node })
+	return nodes, nil
+}
+
+// readNodeMeminfo parses lines such as "Node 0 MemTotal:  4685560 kB"
+func readNodeMeminfo(path string) (domain.NUMANodeMemory, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return domain.NUMANodeMemory{}, err
+	}
+	defer f.Close()
+
+	var usage domain.NUMANodeMemory
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		var dest *uint64
+		switch fields[2] {
+		case "MemTotal:":
+			dest = &usage.Total
+		case "MemUsed:":
+			dest = &usage.Used
+		default:
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			return domain.NUMANodeMemory{}, fmt.Errorf("malformed %s: %w", path, err)
+		}
+		*dest = kb * 1024
+	}
+	return usage, scanner.Err()
+}
+
+// parseCPUList parses kernel CPU lists such as "0-3,8-11,16"
+func parseCPUList(list string) ([]int, error) {
+	var cpus []int
+	if list == "" {
+		return cpus, nil
+	}
+	for _, part := range strings.Split(list, ",") {
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(hi); err != nil {
+				return nil, err
+			}
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+func readIntFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
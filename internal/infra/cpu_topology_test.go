@@ -0,0 +1,115 @@
+package infra
+
+import (
+	"testing"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCPUTopology(t *testing.T) {
+	root := t.TempDir()
+	cpus := []struct {
+		name         string
+		socket, core string
+	}{
+		{"cpu0", "0", "0"},
+		{"cpu1", "0", "1"},
+		{"cpu2", "1", "0"},
+		{"cpu10", "1", "1"},
+	}
+	for _, cpu := range cpus {
+		writeSysfsFile(t, root, "devices/system/cpu/"+cpu.name+"/topology/physical_package_id", cpu.socket+"\n")
+		writeSysfsFile(t, root, "devices/system/cpu/"+cpu.name+"/topology/core_id", cpu.core+"\n")
+	}
+	// Offline CPUs have no topology and are skipped
+	writeSysfsFile(t, root, "devices/system/cpu/cpu3/online", "0\n")
+	writeSysfsFile(t, root, "devices/system/node/node0/cpulist", "0-1\n")
+	writeSysfsFile(t, root, "devices/system/node/node1/cpulist", "2,10\n")
+
+	topology, err := readCPUTopology(root)
+
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []domain.CoreTopology{
+		{CPU: 0, Socket: 0, Node: 0, Core: 0},
+		{CPU: 1, Socket: 0, Node: 0, Core: 1},
+		{CPU: 2, Socket: 1, Node: 1, Core: 0},
+		{CPU: 10, Socket: 1, Node: 1, Core: 1},
+	}, topology)
+}
+
+func TestReadCPUTopologyWithoutNUMA(t *testing.T) {
+	root := t.TempDir()
+	writeSysfsFile(t, root, "devices/system/cpu/cpu0/topology/physical_package_id", "0\n")
+	writeSysfsFile(t, root, "devices/system/cpu/cpu0/topology/core_id", "0\n")
+
+	topology, err := readCPUTopology(root)
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.CoreTopology{{CPU: 0}}, topology)
+}
+
+func TestReadCPUTopologyMalformedCPUList(t *testing.T) {
+	root := t.TempDir()
+	writeSysfsFile(t, root, "devices/system/node/node0/cpulist", "0-x\n")
+
+	_, err := readCPUTopology(root)
+
+	assert.Error(t, err)
+}
+
+func TestReadNUMAMemory(t *testing.T) {
+	root := t.TempDir()
+	for _, node := range []string{"0", "2", "10"} {
+		writeSysfsFile(t, root, "devices/system/node/node"+node+"/meminfo",
+			"Node "+node+" MemTotal:        4096 kB\n"+
+				"Node "+node+" MemFree:         1024 kB\n"+
+				"Node "+node+" MemUsed:         3072 kB\n")
+	}
+
+	nodes, err := readNUMAMemory(root)
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.NUMANodeMemory{
+		{Node: 0, Total: 4096 * 1024, Used: 3072 * 1024},
+		{Node: 2, Total: 4096 * 1024, Used: 3072 * 1024},
+		{Node: 10, Total: 4096 * 1024, Used: 3072 * 1024},
+	}, nodes)
+}
+
+func TestReadNUMAMemoryMalformed(t *testing.T) {
+	root := t.TempDir()
+	writeSysfsFile(t, root, "devices/system/node/node0/meminfo", "Node 0 MemTotal: lots kB\n")
+
+	_, err := readNUMAMemory(root)
+
+	assert.Error(t, err)
+}
+
+func TestParseCPUList(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    []int
+		wantErr bool
+	}{
+		{list: "", want: nil},
+		{list: "3", want: []int{3}},
+		{list: "0-3", want: []int{0, 1, 2, 3}},
+		{list: "0-1,8-9,16", want: []int{0, 1, 8, 9, 16}},
+		{list: "a", wantErr: true},
+		{list: "0-b", wantErr: true},
+		{list: "0,", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.list, func(t *testing.T) {
+			got, err := parseCPUList(tt.list)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
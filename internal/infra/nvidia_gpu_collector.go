@@ -161,5 +161,35 @@ func (c *NvidiaGPUCollector) getMetrics() (domain.GPUMetrics, error) {
 		}
 	}
 
+	metrics.Devices = c.deviceMetrics(count)
 	return metrics, nil
 }
+
+// deviceMetrics reads utilization and memory of every GPU, skipping devices
+// that fail to report
+func (c *NvidiaGPUCollector) deviceMetrics(count int) []domain.GPUDeviceMetrics {
+	devices := make([]domain.GPUDeviceMetrics, 0, count)
+	for i := range count {
+		device, ret := nvml.DeviceGetHandleByIndex(i)
+		if ret != nvml.SUCCESS {
+			continue
+		}
+		utilization, ret := device.GetUtilizationRates()
+		if ret != nvml.SUCCESS {
+			continue
+		}
+		memory, ret := device.GetMemoryInfo()
+		if ret != nvml.SUCCESS {
+			continue
+		}
+		// The UUID is informational; a device without one is still reported
+		uuid, _ := device.GetUUID()
+		devices = append(devices, domain.GPUDeviceMetrics{
+			Index:       i,
+			UUID:        uuid,
+			Usage:       float64(utilization.Gpu),
+			MemoryUsage: c.gpuCalculator.CalculateMemoryPercent(memory.Used, memory.Total),
+		})
+	}
+	return devices
+}
@@ -0,0 +1,37 @@
+package infra
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+)
+
+// HasNvidiaGPU reports whether NVML can be initialized and sees at least one device
+func HasNvidiaGPU() bool {
+	ret := nvml.Init()
+	if ret != nvml.SUCCESS {
+		return false
+	}
+	defer nvml.Shutdown()
+
+	count, ret := nvml.DeviceGetCount()
+	if ret != nvml.SUCCESS {
+		return false
+	}
+
+	return count > 0
+}
+
+// TODO: Implement AMD GPU detection in the future
+// func HasAMDGPU() bool {
+//     // Implementation to detect AMD GPU
+//     return false
+// }
+
+// HasProcFile reports whether a file under /proc exists, for collectors
+// that depend on Linux-specific procfs entries
+func HasProcFile(name string) bool {
+	_, err := os.Stat(filepath.Join(defaultProcRoot, name))
+	return err == nil
+}
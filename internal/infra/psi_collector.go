@@ -0,0 +1,139 @@
+package infra
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+const (
+	defaultProcRoot   = "/proc"
+	defaultCgroupRoot = "/sys/fs/cgroup"
+)
+
+// PSICollector reads Linux Pressure Stall Information for the whole system
+// and, when a cgroup is configured, for that cgroup. Kernels without PSI
+// produce metrics with Available set to false rather than an error.
+type PSICollector struct {
+	*BaseCollector[domain.PSIMetrics]
+	procRoot   string
+	cgroupRoot string
+	cgroup     string
+}
+
+// NewPSICollector creates a PSI collector. cgroup is a path relative to the
+// cgroup v2 mount (for example "system.slice/slurmd.service"); empty disables
+// per-cgroup pressure.
+func NewPSICollector(cgroup string) *PSICollector {
+	collector := &PSICollector{
+		procRoot:   defaultProcRoot,
+		cgroupRoot: defaultCgroupRoot,
+		cgroup:     strings.Trim(cgroup, "/"),
+	}
+	collector.BaseCollector = NewBaseCollector(collector.getMetrics)
+	return collector
+}
+
+func (c *PSICollector) getMetrics() (domain.PSIMetrics, error) {
+	metrics := domain.PSIMetrics{Cgroup: c.cgroup}
+
+	system, err := readPressureSet(func(resource string) string {
+		return filepath.Join(c.procRoot, "pressure", resource)
+	})
+	if err == nil {
+		metrics.Available = true
+		metrics.System = system
+	}
+
+	if c.cgroup != "" {
+		cgroupPressure, err := readPressureSet(func(resource string) string {
+			return filepath.Join(c.cgroupRoot, c.cgroup, resource+".pressure")
+		})
+		if err == nil {
+			metrics.CgroupAvailable = true
+			metrics.CgroupPressure = cgroupPressure
+		}
+	}
+
+	return metrics, nil
+}
+
+// readPressureSet reads cpu, memory and io pressure using pathFor to locate each file
+func readPressureSet(pathFor func(resource string) string) (domain.PressureSet, error) {
+	var set domain.PressureSet
+	targets := []struct {
+		resource string
+		dest     *domain.ResourcePressure
+	}{
+		{"cpu", &set.CPU},
+		{"memory", &set.Memory},
+		{"io", &set.IO},
+	}
+
+	for _, t := range targets {
+		f, err := os.Open(pathFor(t.resource))
+		if err != nil {
+			return domain.PressureSet{}, err
+		}
+		pressure, err := parsePressure(f)
+		f.Close()
+		if err != nil {
+			return domain.PressureSet{}, fmt.Errorf("failed to parse %s pressure: %w", t.resource, err)
+		}
+		*t.dest = pressure
+	}
+	return set, nil
+}
+
+// parsePressure parses the contents of a PSI file:
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func parsePressure(r io.Reader) (domain.ResourcePressure, error) {
+	var pressure domain.ResourcePressure
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		var stats *domain.PressureStats
+		switch fields[0] {
+		case "some":
+			stats = &pressure.Some
+		case "full":
+			stats = &pressure.Full
+		default:
+			continue
+		}
+
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return domain.ResourcePressure{}, fmt.Errorf("malformed field %q", field)
+			}
+			var err error
+			switch key {
+			case "avg10":
+				stats.Avg10, err = strconv.ParseFloat(value, 64)
+			case "avg60":
+				stats.Avg60, err = strconv.ParseFloat(value, 64)
+			case "avg300":
+				stats.Avg300, err = strconv.ParseFloat(value, 64)
+			case "total":
+				stats.Total, err = strconv.ParseUint(value, 10, 64)
+			}
+			if err != nil {
+				return domain.ResourcePressure{}, fmt.Errorf("malformed field %q: %w", field, err)
+			}
+		}
+	}
+	return pressure, scanner.Err()
+}
@@ -0,0 +1,73 @@
+package infra
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePressure(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    domain.ResourcePressure
+		wantErr bool
+	}{
+		{
+			name: "some and full",
+			input: "some avg10=1.50 avg60=0.75 avg300=0.10 total=12345\n" +
+				"full avg10=0.50 avg60=0.25 avg300=0.05 total=678\n",
+			want: domain.ResourcePressure{
+				Some: domain.PressureStats{Avg10: 1.5, Avg60: 0.75, Avg300: 0.1, Total: 12345},
+				Full: domain.PressureStats{Avg10: 0.5, Avg60: 0.25, Avg300: 0.05, Total: 678},
+			},
+		},
+		{
+			name:  "cpu pressure before 5.13 has no full line",
+			input: "some avg10=2.00 avg60=1.00 avg300=0.50 total=99\n",
+			want:  domain.ResourcePressure{Some: domain.PressureStats{Avg10: 2, Avg60: 1, Avg300: 0.5, Total: 99}},
+		},
+		{
+			name:  "blank and unknown lines and keys are skipped",
+			input: "\nother avg10=9.00\nsome avg10=1.00 extra=7 total=1\n",
+			want:  domain.ResourcePressure{Some: domain.PressureStats{Avg10: 1, Total: 1}},
+		},
+		{name: "field without value", input: "some avg10\n", wantErr: true},
+		{name: "bad number", input: "some avg10=high\n", wantErr: true},
+		{name: "negative total", input: "some total=-1\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePressure(strings.NewReader(tt.input))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPSICollectorGetMetrics(t *testing.T) {
+	procRoot, cgroupRoot := t.TempDir(), t.TempDir()
+	for _, resource := range []string{"cpu", "memory", "io"} {
+		writeSysfsFile(t, procRoot, "pressure/"+resource, "some avg10=3.00 avg60=0.00 avg300=0.00 total=0\n")
+	}
+	// The cgroup lacks io.pressure, so its pressure is unavailable
+	writeSysfsFile(t, cgroupRoot, "system.slice/cpu.pressure", "some avg10=1.00 avg60=0.00 avg300=0.00 total=0\n")
+
+	collector := NewPSICollector("/system.slice/")
+	collector.procRoot = procRoot
+	collector.cgroupRoot = cgroupRoot
+	metrics, err := collector.getMetrics()
+
+	require.NoError(t, err)
+	assert.True(t, metrics.Available)
+	assert.Equal(t, 3.0, metrics.System.Memory.Some.Avg10)
+	assert.Equal(t, "system.slice", metrics.Cgroup)
+	assert.False(t, metrics.CgroupAvailable)
+}
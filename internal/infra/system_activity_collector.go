@@ -0,0 +1,113 @@
+package infra
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+// SystemActivityCollector reads load averages from /proc/loadavg and
+// scheduler counters from /proc/stat
+type SystemActivityCollector struct {
+	*BaseCollector[domain.SystemActivityMetrics]
+	procRoot           string
+	lastCounters       domain.ActivityCounters
+	lastCollectTime    time.Time
+	activityCalculator *domain.ActivityCalculator
+}
+
+func NewSystemActivityCollector() *SystemActivityCollector {
+	collector := &SystemActivityCollector{
+		procRoot:           defaultProcRoot,
+		activityCalculator: domain.NewActivityCalculator(),
+	}
+	collector.BaseCollector = NewBaseCollector(collector.getMetrics)
+	return collector
+}
+
+func (c *SystemActivityCollector) getMetrics() (domain.SystemActivityMetrics, error) {
+	currentTime := time.Now()
+
+	var counters domain.ActivityCounters
+	if err := c.readLoadAvg(&counters); err != nil {
+		return domain.SystemActivityMetrics{}, err
+	}
+	if err := c.readStat(&counters); err != nil {
+		return domain.SystemActivityMetrics{}, err
+	}
+
+	var deltaTime float64
+	if !c.lastCollectTime.IsZero() {
+		deltaTime = currentTime.Sub(c.lastCollectTime).Seconds()
+	}
+	metrics := c.activityCalculator.CalculateActivity(counters, c.lastCounters, deltaTime)
+
+	c.lastCounters = counters
+	c.lastCollectTime = currentTime
+	return metrics, nil
+}
+
+// readLoadAvg parses "0.52 0.58 0.59 2/1024 12345"
+func (c *SystemActivityCollector) readLoadAvg(counters *domain.ActivityCounters) error {
+	data, err := os.ReadFile(filepath.Join(c.procRoot, "loadavg"))
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return fmt.Errorf("malformed loadavg: %q", data)
+	}
+
+	loads := []*float64{&counters.Load1, &counters.Load5, &counters.Load15}
+	for i, dest := range loads {
+		if *dest, err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return fmt.Errorf("malformed loadavg: %w", err)
+		}
+	}
+	return nil
+}
+
+// readStat extracts the scheduler counters from /proc/stat
+func (c *SystemActivityCollector) readStat(counters *domain.ActivityCounters) error {
+	f, err := os.Open(filepath.Join(c.procRoot, "stat"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // intr lines list every IRQ
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		var dest *uint64
+		switch fields[0] {
+		case "ctxt":
+			dest = &counters.ContextSwitches
+		case "intr":
+			dest = &counters.Interrupts // first value is the total
+		case "processes":
+			dest = &counters.Forks
+		case "procs_running":
+			dest = &counters.ProcsRunning
+		case "procs_blocked":
+			dest = &counters.ProcsBlocked
+		default:
+			continue
+		}
+
+		if *dest, err = strconv.ParseUint(fields[1], 10, 64); err != nil {
+			return fmt.Errorf("malformed %s in stat: %w", fields[0], err)
+		}
+	}
+	return scanner.Err()
+}
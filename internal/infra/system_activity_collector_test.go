@@ -0,0 +1,97 @@
+package infra
+
+import (
+	"testing"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemActivityCollectorReadLoadAvg(t *testing.T) {
+	tests := []struct {
+		name    string
+		loadavg string
+		want    domain.ActivityCounters
+		wantErr bool
+	}{
+		{
+			name:    "typical",
+			loadavg: "0.52 0.58 0.59 2/1024 12345\n",
+			want:    domain.ActivityCounters{Load1: 0.52, Load5: 0.58, Load15: 0.59},
+		},
+		{name: "too few fields", loadavg: "0.52 0.58\n", wantErr: true},
+		{name: "not a number", loadavg: "0.52 high 0.59 2/1024 12345\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeSysfsFile(t, root, "loadavg", tt.loadavg)
+			collector := &SystemActivityCollector{procRoot: root}
+
+			var counters domain.ActivityCounters
+			err := collector.readLoadAvg(&counters)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, counters)
+		})
+	}
+}
+
+func TestSystemActivityCollectorReadStat(t *testing.T) {
+	tests := []struct {
+		name    string
+		stat    string
+		want    domain.ActivityCounters
+		wantErr bool
+	}{
+		{
+			name: "typical",
+			stat: "cpu  10 0 20 300 0 0 0 0 0 0\n" +
+				"cpu0 10 0 20 300 0 0 0 0 0 0\n" +
+				"intr 5000 12 0 7 0\n" +
+				"ctxt 90000\n" +
+				"btime 1700000000\n" +
+				"processes 4321\n" +
+				"procs_running 3\n" +
+				"procs_blocked 1\n" +
+				"softirq 100 1 2 3\n",
+			want: domain.ActivityCounters{ContextSwitches: 90000, Interrupts: 5000, Forks: 4321, ProcsRunning: 3, ProcsBlocked: 1},
+		},
+		{
+			name: "missing counters stay zero",
+			stat: "ctxt 7\n\nprocs_running\n",
+			want: domain.ActivityCounters{ContextSwitches: 7},
+		},
+		{name: "malformed counter", stat: "ctxt many\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeSysfsFile(t, root, "stat", tt.stat)
+			collector := &SystemActivityCollector{procRoot: root}
+
+			var counters domain.ActivityCounters
+			err := collector.readStat(&counters)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, counters)
+		})
+	}
+}
+
+func TestSystemActivityCollectorWithoutProc(t *testing.T) {
+	collector := &SystemActivityCollector{procRoot: t.TempDir()}
+
+	var counters domain.ActivityCounters
+	assert.Error(t, collector.readLoadAvg(&counters))
+	assert.Error(t, collector.readStat(&counters))
+}
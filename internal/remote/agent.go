@@ -0,0 +1,244 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// handshakeTimeout bounds how long a client may take to say hello
+	handshakeTimeout = 10 * time.Second
+	// subscriberBuffer is how many samples a slow client may fall behind
+	// before samples are dropped for it
+	subscriberBuffer = 16
+	// recordBuffer is larger since a recording should not lose samples
+	recordBuffer = 256
+)
+
+// Agent publishes collector streams to any number of clients
+type Agent struct {
+	hostname    string
+	mu          sync.Mutex
+	streams     map[string]bool
+	subscribers map[string]map[chan []byte]bool
+	conns       map[net.Conn]bool
+	heartbeat   time.Duration // silence after which a client gets a heartbeat
+}
+
+func NewAgent() *Agent {
+	hostname, _ := os.Hostname()
+	return &Agent{
+		hostname:    hostname,
+		streams:     make(map[string]bool),
+		subscribers: make(map[string]map[chan []byte]bool),
+		conns:       make(map[net.Conn]bool),
+		heartbeat:   heartbeatInterval,
+	}
+}
+
+// Publish streams every value received on values to the clients subscribed
+// to name, until values is closed
+func (a *Agent) Publish(name string, values <-chan any) {
+	a.mu.Lock()
+	a.streams[name] = true
+	a.mu.Unlock()
+
+	go func() {
+		for value := range values {
+			data, err := json.Marshal(value)
+			if err != nil {
+				log.Printf("agent: encoding %s sample: %v", name, err)
+				continue
+			}
+			line, err := json.Marshal(frame{Type: frameMetrics, Stream: name, Time: time.Now().UnixMilli(), Data: data})
+			if err != nil {
+				continue
+			}
+			a.broadcast(name, append(line, '\n'))
+		}
+	}()
+}
+
+func (a *Agent) broadcast(name string, line []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for sub := range a.subscribers[name] {
+		select {
+		case sub <- line:
+		default:
+			// The client is not keeping up; it misses this sample
+		}
+	}
+}
+
+// Streams returns the published stream names
+func (a *Agent) Streams() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	names := make([]string, 0, len(a.streams))
+	for name := range a.streams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Serve accepts clients on l until it is closed
+func (a *Agent) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		go a.handle(conn)
+	}
+}
+
+// Close disconnects every client; listeners are closed by their owner
+func (a *Agent) Close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for conn := range a.conns {
+		conn.Close()
+	}
+}
+
+// handle performs the handshake and then streams samples to one client
+func (a *Agent) handle(conn net.Conn) {
+	a.mu.Lock()
+	a.conns[conn] = true
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		delete(a.conns, conn)
+		a.mu.Unlock()
+		conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(handshakeTimeout))
+	var hello frame
+	if err := readFrame(reader, &hello); err != nil || hello.Type != frameHello {
+		writeFrame(conn, frame{Type: frameError, Error: "expected hello"})
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	version, ok := negotiate(hello.Versions)
+	if !ok {
+		writeFrame(conn, frame{Type: frameError, Error: fmt.Sprintf(
+			"no common protocol version: client speaks %v, agent speaks %v", hello.Versions, supportedVersions)})
+		return
+	}
+	for _, name := range hello.Streams {
+		if !a.hasStream(name) {
+			writeFrame(conn, frame{Type: frameError, Error: fmt.Sprintf("agent does not publish %q", name)})
+			return
+		}
+	}
+	if err := writeFrame(conn, frame{Type: frameWelcome, Version: version, Hostname: a.hostname, Streams: a.Streams()}); err != nil {
+		return
+	}
+
+	sub := make(chan []byte, subscriberBuffer)
+	a.subscribe(hello.Streams, sub)
+	defer a.unsubscribe(hello.Streams, sub)
+
+	// Clients send nothing after hello; a read returns when they go away
+	done := make(chan struct{})
+	go func() {
+		reader.Discard(1)
+		close(done)
+	}()
+
+	heartbeat := time.NewTimer(a.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case line := <-sub:
+			if _, err := conn.Write(line); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := writeFrame(conn, frame{Type: frameHeartbeat}); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+		heartbeat.Reset(a.heartbeat)
+	}
+}
+
+// Record writes a recording of every published stream to w until writing
+// fails. Streams must be published before recording starts.
+func (a *Agent) Record(w io.Writer) error {
+	streams := a.Streams()
+	if err := writeFrame(w, frame{Type: frameWelcome, Version: ProtocolVersion, Hostname: a.hostname, Streams: streams}); err != nil {
+		return err
+	}
+
+	sub := make(chan []byte, recordBuffer)
+	a.subscribe(streams, sub)
+	defer a.unsubscribe(streams, sub)
+	for line := range sub {
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Agent) hasStream(name string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.streams[name]
+}
+
+func (a *Agent) subscribe(names []string, sub chan []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, name := range names {
+		if a.subscribers[name] == nil {
+			a.subscribers[name] = make(map[chan []byte]bool)
+		}
+		a.subscribers[name][sub] = true
+	}
+}
+
+func (a *Agent) unsubscribe(names []string, sub chan []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, name := range names {
+		delete(a.subscribers[name], sub)
+	}
+}
+
+func readFrame(reader *bufio.Reader, f *frame) error {
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(line, f)
+}
+
+func writeFrame(w io.Writer, f frame) error {
+	line, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
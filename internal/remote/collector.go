@@ -0,0 +1,271 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	dialTimeout = 5 * time.Second
+	// Reconnect delays start small and double up to the maximum
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+	// maxReplayGap shortens pauses in a recording, such as an agent restart
+	maxReplayGap = 10 * time.Second
+)
+
+// Info describes an agent as reported in its welcome
+type Info struct {
+	Hostname string
+	Version  int
+	Streams  []string
+}
+
+// Collector receives one stream from an agent. It satisfies the same
+// Start/Stop/Metrics contract as the local collectors and reconnects with
+// backoff whenever the connection drops. Given a recording it replays the
+// stream once and then closes its metrics channel.
+type Collector[T any] struct {
+	address string
+	stream  string
+	metrics chan T
+	stop    chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	conn    io.Closer
+	timeout time.Duration // silence after which the connection is redialled
+}
+
+func NewCollector[T any](address, stream string) *Collector[T] {
+	return &Collector[T]{
+		address: address,
+		stream:  stream,
+		metrics: make(chan T),
+		stop:    make(chan struct{}),
+		timeout: missedHeartbeats * heartbeatInterval,
+	}
+}
+
+func (c *Collector[T]) Start() {
+	go c.run()
+}
+
+// Stop disconnects; the metrics channel is closed once the receive loop
+// has exited
+func (c *Collector[T]) Stop() {
+	c.once.Do(func() {
+		close(c.stop)
+		c.mu.Lock()
+		if c.conn != nil {
+			c.conn.Close()
+		}
+		c.mu.Unlock()
+	})
+}
+
+func (c *Collector[T]) Metrics() <-chan T {
+	return c.metrics
+}
+
+func (c *Collector[T]) run() {
+	defer close(c.metrics)
+
+	delay := minReconnectDelay
+	for {
+		received, err := c.receive()
+		if c.stopped() {
+			return
+		}
+		if isRecording(c.address) {
+			if err != io.EOF {
+				log.Printf("replaying %s %s: %v", c.address, c.stream, err)
+			}
+			return
+		}
+		if received {
+			delay = minReconnectDelay
+		}
+		log.Printf("remote %s %s: %v; reconnecting in %v", c.address, c.stream, err, delay)
+
+		select {
+		case <-time.After(delay):
+		case <-c.stop:
+			return
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+func (c *Collector[T]) stopped() bool {
+	select {
+	case <-c.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// receive connects and forwards samples until the connection fails. It
+// reports whether any sample arrived, so a working connection resets the
+// backoff.
+func (c *Collector[T]) receive() (bool, error) {
+	conn, reader, _, err := open(c.address, []string{c.stream})
+	if err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	c.conn = conn
+	c.mu.Unlock()
+	defer conn.Close()
+	if c.stopped() {
+		return false, nil
+	}
+
+	received := false
+	replay := isRecording(c.address)
+	// An agent sends at least a heartbeat while connected, so a silent
+	// connection is one whose host has gone away without closing it
+	deadline, _ := conn.(interface{ SetReadDeadline(time.Time) error })
+	var last int64 // time of the previous sample, for pacing a replay
+	for {
+		if deadline != nil {
+			deadline.SetReadDeadline(time.Now().Add(c.timeout))
+		}
+		var f frame
+		if err := readFrame(reader, &f); err != nil {
+			return received, err
+		}
+		switch f.Type {
+		case frameError:
+			return received, fmt.Errorf("agent error: %s", f.Error)
+		case frameMetrics:
+			if f.Stream != c.stream {
+				continue
+			}
+			var value T
+			if err := json.Unmarshal(f.Data, &value); err != nil {
+				return received, fmt.Errorf("decoding %s sample: %w", c.stream, err)
+			}
+			if replay && last != 0 {
+				select {
+				case <-time.After(min(time.Duration(f.Time-last)*time.Millisecond, maxReplayGap)):
+				case <-c.stop:
+					return received, nil
+				}
+			}
+			last = f.Time
+			select {
+			case c.metrics <- value:
+				received = true
+			case <-c.stop:
+				return received, nil
+			}
+		}
+	}
+}
+
+// Probe connects to an agent, or reads a recording, and returns its welcome
+// without subscribing
+func Probe(address string) (Info, error) {
+	conn, _, info, err := open(address, nil)
+	if err != nil {
+		return Info{}, err
+	}
+	conn.Close()
+	return info, nil
+}
+
+// ReadRecording reads a whole recording, without pacing, calling fn with the
+// time, stream and JSON data of every sample in order
+func ReadRecording(path string, fn func(at time.Time, stream string, data json.RawMessage) error) (Info, error) {
+	file, reader, info, err := openRecording(path, nil)
+	if err != nil {
+		return Info{}, err
+	}
+	defer file.Close()
+	for {
+		var f frame
+		err := readFrame(reader, &f)
+		if err == io.EOF {
+			return info, nil
+		}
+		if err != nil {
+			return info, fmt.Errorf("reading %s: %w", path, err)
+		}
+		if f.Type != frameMetrics {
+			continue
+		}
+		if err := fn(time.UnixMilli(f.Time), f.Stream, f.Data); err != nil {
+			return info, err
+		}
+	}
+}
+
+// open starts reading the given streams from an agent or a recording
+func open(address string, streams []string) (io.ReadCloser, *bufio.Reader, Info, error) {
+	if isRecording(address) {
+		return openRecording(address, streams)
+	}
+	return handshake(address, streams)
+}
+
+// openRecording reads the welcome at the start of a recording
+func openRecording(path string, streams []string) (io.ReadCloser, *bufio.Reader, Info, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, Info{}, err
+	}
+	reader := bufio.NewReader(file)
+	var welcome frame
+	err = readFrame(reader, &welcome)
+	if err == nil && welcome.Type != frameWelcome {
+		err = fmt.Errorf("%s is not a mim recording", path)
+	}
+	for _, name := range streams {
+		if err == nil && !slices.Contains(welcome.Streams, name) {
+			err = fmt.Errorf("recording %s has no %q stream", path, name)
+		}
+	}
+	if err != nil {
+		file.Close()
+		return nil, nil, Info{}, err
+	}
+	return file, reader, Info{Hostname: welcome.Hostname, Version: welcome.Version, Streams: welcome.Streams}, nil
+}
+
+// handshake dials the agent, sends hello and reads the welcome
+func handshake(address string, streams []string) (io.ReadCloser, *bufio.Reader, Info, error) {
+	network, addr := splitAddress(address)
+	conn, err := net.DialTimeout(network, addr, dialTimeout)
+	if err != nil {
+		return nil, nil, Info{}, err
+	}
+
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	reader := bufio.NewReader(conn)
+	var welcome frame
+	err = writeFrame(conn, frame{Type: frameHello, Versions: supportedVersions, Streams: streams})
+	if err == nil {
+		err = readFrame(reader, &welcome)
+	}
+	if err == nil && welcome.Type == frameError {
+		err = fmt.Errorf("agent refused connection: %s", welcome.Error)
+	} else if err == nil && (welcome.Type != frameWelcome || !slices.Contains(supportedVersions, welcome.Version)) {
+		err = fmt.Errorf("unexpected handshake reply %q (version %d)", welcome.Type, welcome.Version)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, nil, Info{}, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	return conn, reader, Info{Hostname: welcome.Hostname, Version: welcome.Version, Streams: welcome.Streams}, nil
+}
@@ -0,0 +1,101 @@
+// Package remote streams collector metrics between a mim agent and a TUI on
+// another host.
+//
+// The wire protocol is newline-delimited JSON over TCP or a unix socket. The
+// client opens with a hello frame listing the protocol versions it speaks and
+// the streams it wants; the agent answers with a welcome frame naming the
+// chosen version, or an error frame, and then sends one metrics frame per
+// collector sample. While no sample is due it sends a heartbeat frame every
+// heartbeatInterval, so a client can tell a quiet agent from a vanished
+// one. Stream names are collector names such as "cpu".
+//
+// A recording is the same frames written to a file: a welcome frame followed
+// by timestamped metrics frames. Anywhere an agent address is accepted, a
+// recording's path replays it at the recorded pace.
+package remote
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// ProtocolVersion is the newest protocol version this build speaks
+const ProtocolVersion = 1
+
+// supportedVersions lists every protocol version this build speaks
+var supportedVersions = []int{ProtocolVersion}
+
+// Frame types
+const (
+	frameHello     = "hello"
+	frameWelcome   = "welcome"
+	frameMetrics   = "metrics"
+	frameHeartbeat = "heartbeat"
+	frameError     = "error"
+)
+
+const (
+	// heartbeatInterval is how long an agent's connection may stay silent
+	// before it sends a heartbeat
+	heartbeatInterval = 5 * time.Second
+	// missedHeartbeats is how many heartbeat intervals a client waits for a
+	// frame before it reconnects
+	missedHeartbeats = 3
+)
+
+// frame is one line on the wire. Only the fields of its type are set.
+type frame struct {
+	Type string `json:"type"`
+	// hello: versions offered; welcome: the version chosen
+	Versions []int `json:"versions,omitempty"`
+	Version  int   `json:"version,omitempty"`
+	// hello: streams wanted; welcome: streams the agent publishes
+	Streams  []string `json:"streams,omitempty"`
+	Hostname string   `json:"hostname,omitempty"`
+	// metrics: the stream and its sample
+	Stream string          `json:"stream,omitempty"`
+	Time   int64           `json:"time,omitempty"` // Unix milliseconds
+	Data   json.RawMessage `json:"data,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// negotiate picks the newest version both sides speak
+func negotiate(offered []int) (int, bool) {
+	best := 0
+	for _, v := range offered {
+		for _, supported := range supportedVersions {
+			if v == supported && v > best {
+				best = v
+			}
+		}
+	}
+	return best, best > 0
+}
+
+// splitAddress maps "unix:/path" and paths to unix sockets and anything
+// else to TCP
+func splitAddress(address string) (network, addr string) {
+	if path, ok := strings.CutPrefix(address, "unix:"); ok {
+		return "unix", path
+	}
+	if strings.HasPrefix(address, "/") || strings.HasPrefix(address, "./") {
+		return "unix", address
+	}
+	return "tcp", address
+}
+
+// isRecording reports whether address names a recording rather than an
+// agent
+func isRecording(address string) bool {
+	info, err := os.Stat(address)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// Listen opens a listener for an agent address
+func Listen(address string) (net.Listener, error) {
+	network, addr := splitAddress(address)
+	return net.Listen(network, addr)
+}
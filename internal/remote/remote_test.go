@@ -0,0 +1,205 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receive[T any](t *testing.T, metrics <-chan T) T {
+	t.Helper()
+	select {
+	case value := <-metrics:
+		return value
+	case <-time.After(5 * time.Second):
+		t.Fatal("no sample received")
+		var zero T
+		return zero
+	}
+}
+
+// publishEvery sends value on a fresh channel until the test ends
+func publishEvery(t *testing.T, agent *Agent, name string, value any) {
+	values := make(chan any)
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	go func() {
+		defer close(values)
+		for {
+			select {
+			case values <- value:
+				time.Sleep(10 * time.Millisecond)
+			case <-done:
+				return
+			}
+		}
+	}()
+	agent.Publish(name, values)
+}
+
+func TestCollectorReceivesAgentStream(t *testing.T) {
+	address := "unix:" + filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := Listen(address)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	agent := NewAgent()
+	publishEvery(t, agent, "cpu", domain.CPUMemoryMetrics{CPUUsageTotal: 42, Processes: []domain.CPUProcessInfo{{Pid: 7, Command: "job"}}})
+	go agent.Serve(listener)
+
+	info, err := Probe(address)
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolVersion, info.Version)
+	assert.Equal(t, []string{"cpu"}, info.Streams)
+
+	collector := NewCollector[domain.CPUMemoryMetrics](address, "cpu")
+	collector.Start()
+	metrics := receive(t, collector.Metrics())
+	assert.Equal(t, 42.0, metrics.CPUUsageTotal)
+	assert.Equal(t, "job", metrics.Processes[0].Command)
+
+	collector.Stop()
+	for range collector.Metrics() {
+	}
+}
+
+func TestAgentRejectsUnknownVersion(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go NewAgent().Serve(listener)
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, writeFrame(conn, frame{Type: frameHello, Versions: []int{99}}))
+
+	var reply frame
+	require.NoError(t, readFrame(bufio.NewReader(conn), &reply))
+	assert.Equal(t, frameError, reply.Type)
+	assert.Contains(t, reply.Error, "no common protocol version")
+}
+
+func TestCollectorReconnects(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+
+	first := NewAgent()
+	publishEvery(t, first, "nvidia", domain.GPUMetrics{GPUUsage: 1})
+	go first.Serve(listener)
+
+	collector := NewCollector[domain.GPUMetrics](address, "nvidia")
+	collector.Start()
+	defer collector.Stop()
+	assert.Equal(t, 1.0, receive(t, collector.Metrics()).GPUUsage)
+
+	// Restart the agent on the same address with different samples
+	listener.Close()
+	first.Close()
+	listener, err = net.Listen("tcp", address)
+	require.NoError(t, err)
+	defer listener.Close()
+	second := NewAgent()
+	publishEvery(t, second, "nvidia", domain.GPUMetrics{GPUUsage: 2})
+	go second.Serve(listener)
+
+	assert.Eventually(t, func() bool {
+		return receive(t, collector.Metrics()).GPUUsage == 2
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func TestCollectorReplaysRecording(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "box.ndjson")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, writeFrame(file, frame{Type: frameWelcome, Version: ProtocolVersion, Hostname: "box", Streams: []string{"cpu"}}))
+	for i, usage := range []string{"10", "20"} {
+		require.NoError(t, writeFrame(file, frame{Type: frameMetrics, Stream: "cpu", Time: int64(1000 + 10*i), Data: []byte(`{"CPUUsageTotal":` + usage + `}`)}))
+	}
+	require.NoError(t, file.Close())
+
+	info, err := Probe(path)
+	require.NoError(t, err)
+	assert.Equal(t, "box", info.Hostname)
+	assert.Equal(t, []string{"cpu"}, info.Streams)
+
+	collector := NewCollector[domain.CPUMemoryMetrics](path, "cpu")
+	collector.Start()
+	var usage []float64
+	for metrics := range collector.Metrics() {
+		usage = append(usage, metrics.CPUUsageTotal)
+	}
+	assert.Equal(t, []float64{10, 20}, usage)
+
+	var times []int64
+	info, err = ReadRecording(path, func(at time.Time, stream string, data json.RawMessage) error {
+		times = append(times, at.UnixMilli())
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "box", info.Hostname)
+	assert.Equal(t, []int64{1000, 1010}, times)
+
+	_, err = Probe(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestHeartbeat(t *testing.T) {
+	// An agent whose stream is idle still sends heartbeats
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	agent := NewAgent()
+	agent.heartbeat = 20 * time.Millisecond
+	agent.Publish("cpu", make(chan any))
+	go agent.Serve(listener)
+
+	conn, reader, _, err := handshake(listener.Addr().String(), []string{"cpu"})
+	require.NoError(t, err)
+	var f frame
+	require.NoError(t, readFrame(reader, &f))
+	assert.Equal(t, frameHeartbeat, f.Type)
+	conn.Close()
+
+	// A connection that goes silent, as when the agent's host vanishes, is
+	// given up and redialled
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer silent.Close()
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := silent.Accept()
+			if err != nil {
+				return
+			}
+			reader := bufio.NewReader(conn)
+			var hello frame
+			readFrame(reader, &hello)
+			writeFrame(conn, frame{Type: frameWelcome, Version: ProtocolVersion, Streams: []string{"cpu"}})
+			accepted <- conn
+		}
+	}()
+
+	collector := NewCollector[domain.CPUMemoryMetrics](silent.Addr().String(), "cpu")
+	collector.timeout = 50 * time.Millisecond
+	collector.Start()
+	defer collector.Stop()
+	for range 2 {
+		select {
+		case conn := <-accepted:
+			defer conn.Close()
+		case <-time.After(5 * time.Second):
+			t.Fatal("collector did not reconnect")
+		}
+	}
+}
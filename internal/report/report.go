@@ -0,0 +1,236 @@
+// Package report renders a recorded session as a self-contained HTML page.
+//
+// The page has inline SVG charts of CPU, memory and GPU utilization, a
+// per-core heatmap over time, the busiest processes of each interval and
+// summary statistics. It uses no scripts, stylesheets, fonts or images from
+// elsewhere, so it can be attached to a ticket or mailed as it is.
+package report
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/jonsampson/mim/internal/store"
+)
+
+const (
+	// intervals is how many intervals the process tables split a session into
+	intervals = 10
+	// topProcesses is how many processes each interval lists
+	topProcesses = 5
+	// keptProcesses is how many of each sample's busiest processes are kept
+	keptProcesses = 20
+)
+
+// sample is one CPU sample with the GPU values current at the time
+type sample struct {
+	store.Sample
+	cpuProcesses []domain.CPUProcessInfo
+	gpuProcesses []domain.GPUProcessInfo
+}
+
+// Report collects the samples of a session
+type Report struct {
+	host    string
+	samples []sample
+	gpu     *domain.GPUMetrics
+}
+
+// New starts a report of the named host
+func New(host string) *Report {
+	return &Report{host: host}
+}
+
+// Add records a collector value. As with CSV rows, every CPU value is a
+// sample, together with the latest GPU value.
+func (r *Report) Add(at time.Time, value any) {
+	switch msg := value.(type) {
+	case domain.GPUMetrics:
+		r.gpu = &msg
+	case domain.CPUMemoryMetrics:
+		s := sample{Sample: store.Sample{Time: at, CPU: msg.CPUUsageTotal, Cores: msg.CPUUsagePerCore, Memory: msg.MemoryUsage}}
+		s.cpuProcesses = append([]domain.CPUProcessInfo(nil), msg.Processes...)
+		sort.Slice(s.cpuProcesses, func(i, j int) bool { return s.cpuProcesses[i].CPUPercent > s.cpuProcesses[j].CPUPercent })
+		s.cpuProcesses = s.cpuProcesses[:min(keptProcesses, len(s.cpuProcesses))]
+		if r.gpu != nil {
+			for _, device := range devices(*r.gpu) {
+				s.GPUs = append(s.GPUs, store.GPU{Usage: device.Usage, Memory: device.MemoryUsage})
+			}
+			s.gpuProcesses = append([]domain.GPUProcessInfo(nil), r.gpu.Processes...)
+			sort.Slice(s.gpuProcesses, func(i, j int) bool { return s.gpuProcesses[i].SmUtil > s.gpuProcesses[j].SmUtil })
+			s.gpuProcesses = s.gpuProcesses[:min(keptProcesses, len(s.gpuProcesses))]
+		}
+		r.samples = append(r.samples, s)
+	}
+}
+
+// devices returns per-GPU metrics, or the aggregate as a single GPU
+func devices(gpu domain.GPUMetrics) []domain.GPUDeviceMetrics {
+	if len(gpu.Devices) == 0 {
+		return []domain.GPUDeviceMetrics{{Usage: gpu.GPUUsage, MemoryUsage: gpu.GPUMemoryUsage}}
+	}
+	return gpu.Devices
+}
+
+// page is what the template renders
+type page struct {
+	Host      string
+	Start     string
+	End       string
+	Duration  time.Duration
+	Samples   int
+	Stats     []store.Stat
+	Charts    []template.HTML
+	Heatmap   template.HTML
+	Intervals []interval
+	GPU       bool // the GPU process column is shown
+}
+
+// interval lists the busiest processes of part of the session
+type interval struct {
+	From      string
+	To        string
+	Processes []processRow
+}
+
+// processRow is a process's average usage over an interval
+type processRow struct {
+	PID     uint32
+	User    string
+	Command string
+	CPU     float64
+	Memory  float64
+	GPU     float64
+}
+
+// Write renders the report as HTML
+func (r *Report) Write(w io.Writer) error {
+	if len(r.samples) == 0 {
+		return errors.New("no CPU samples to report")
+	}
+	start, end := r.samples[0].Time, r.samples[len(r.samples)-1].Time
+	samples := make([]store.Sample, len(r.samples))
+	for i, s := range r.samples {
+		samples[i] = s.Sample
+	}
+
+	p := page{
+		Host:      r.host,
+		Start:     start.Format(time.DateTime),
+		End:       end.Format(time.DateTime),
+		Duration:  end.Sub(start).Round(time.Second),
+		Samples:   len(samples),
+		Stats:     store.Summarize(samples),
+		Heatmap:   heatmap(samples),
+		Intervals: r.intervals(),
+	}
+	p.Charts = append(p.Charts,
+		lineChart("CPU", samples, []series{{name: "total", color: colorCPU, value: func(s store.Sample) (float64, bool) { return s.CPU, true }}}),
+		lineChart("Memory", samples, []series{{name: "memory", color: colorMemory, value: func(s store.Sample) (float64, bool) { return s.Memory, true }}}),
+	)
+	if gpus := gpuCount(samples); gpus > 0 {
+		p.GPU = true
+		var usage, memory []series
+		for i := range gpus {
+			color := gpuColors[i%len(gpuColors)]
+			usage = append(usage, series{name: fmt.Sprintf("GPU %d", i), color: color, value: func(s store.Sample) (float64, bool) {
+				if i < len(s.GPUs) {
+					return s.GPUs[i].Usage, true
+				}
+				return 0, false
+			}})
+			memory = append(memory, series{name: fmt.Sprintf("GPU %d", i), color: color, value: func(s store.Sample) (float64, bool) {
+				if i < len(s.GPUs) {
+					return s.GPUs[i].Memory, true
+				}
+				return 0, false
+			}})
+		}
+		p.Charts = append(p.Charts, lineChart("GPU utilization", samples, usage), lineChart("GPU memory", samples, memory))
+	}
+	return pageTemplate.Execute(w, p)
+}
+
+func gpuCount(samples []store.Sample) int {
+	gpus := 0
+	for _, s := range samples {
+		gpus = max(gpus, len(s.GPUs))
+	}
+	return gpus
+}
+
+// intervals splits the session into equal intervals, at most one per
+// second, and ranks the processes of each by their average CPU plus GPU
+// utilization
+func (r *Report) intervals() []interval {
+	start, end := r.samples[0].Time, r.samples[len(r.samples)-1].Time
+	span := end.Sub(start)
+	count := max(1, min(intervals, int(span/time.Second)))
+
+	type usage struct {
+		row           processRow
+		cpu, mem, gpu float64
+	}
+	byPID := make([]map[uint32]*usage, count)
+	samples := make([]int, count)
+	for i := range byPID {
+		byPID[i] = make(map[uint32]*usage)
+	}
+	for _, s := range r.samples {
+		i := count - 1
+		if span > 0 {
+			i = min(count-1, int(int64(s.Time.Sub(start))*int64(count)/int64(span)))
+		}
+		samples[i]++
+		get := func(pid uint32) *usage {
+			if byPID[i][pid] == nil {
+				byPID[i][pid] = &usage{row: processRow{PID: pid}}
+			}
+			return byPID[i][pid]
+		}
+		for _, p := range s.cpuProcesses {
+			u := get(p.Pid)
+			u.row.User, u.row.Command = p.User, p.Command
+			u.cpu += p.CPUPercent
+			u.mem += p.MemoryPercent
+		}
+		for _, p := range s.gpuProcesses {
+			u := get(p.Pid)
+			if u.row.User == "" {
+				u.row.User = p.User
+			}
+			u.gpu += float64(p.SmUtil)
+		}
+	}
+
+	result := make([]interval, 0, count)
+	for i := range count {
+		if samples[i] == 0 {
+			continue
+		}
+		n := float64(samples[i])
+		rows := make([]processRow, 0, len(byPID[i]))
+		for _, u := range byPID[i] {
+			row := u.row
+			row.CPU, row.Memory, row.GPU = u.cpu/n, u.mem/n, u.gpu/n
+			rows = append(rows, row)
+		}
+		sort.Slice(rows, func(a, b int) bool {
+			if x, y := rows[a].CPU+rows[a].GPU, rows[b].CPU+rows[b].GPU; x != y {
+				return x > y
+			}
+			return rows[a].PID < rows[b].PID
+		})
+		result = append(result, interval{
+			From:      start.Add(span * time.Duration(i) / time.Duration(count)).Format(time.TimeOnly),
+			To:        start.Add(span * time.Duration(i+1) / time.Duration(count)).Format(time.TimeOnly),
+			Processes: rows[:min(topProcesses, len(rows))],
+		})
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	r := New("box")
+	var out strings.Builder
+	assert.Error(t, r.Write(&out))
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	r.Add(start, domain.CPUMemoryMetrics{CPUUsageTotal: 10, CPUUsagePerCore: []float64{0, 20}, MemoryUsage: 40})
+	r.Add(start.Add(500*time.Millisecond), domain.GPUMetrics{
+		Devices:   []domain.GPUDeviceMetrics{{Index: 0, Usage: 90, MemoryUsage: 30}},
+		Processes: []domain.GPUProcessInfo{{Pid: 7, SmUtil: 80, User: "bob"}},
+	})
+	for i := 1; i < 20; i++ {
+		r.Add(start.Add(time.Duration(i)*time.Second), domain.CPUMemoryMetrics{
+			CPUUsageTotal:   50,
+			CPUUsagePerCore: []float64{100, 0},
+			MemoryUsage:     60,
+			Processes:       []domain.CPUProcessInfo{{Pid: 42, CPUPercent: 100, MemoryPercent: 2, User: "ann", Command: "<train>"}},
+		})
+	}
+	require.NoError(t, r.Write(&out))
+	page := out.String()
+
+	assert.Contains(t, page, "<h1>mim report: box</h1>")
+	assert.Contains(t, page, "2024-05-01 12:00:00 to 2024-05-01 12:00:19 (19s), 20 samples")
+	assert.Contains(t, page, `<td class="text">cpu</td><td>10.0%</td><td>48.0%</td><td>50.0%</td><td>50.0%</td>`)
+	assert.Contains(t, page, `<td class="text">gpu0</td><td>90.0%</td>`)
+	// CPU, memory, GPU utilization and GPU memory charts and the heatmap
+	assert.Equal(t, 5, strings.Count(page, "<svg "))
+	assert.Contains(t, page, `<figcaption>GPU utilization</figcaption>`)
+
+	// The first interval holds the sample before the GPU and the first after
+	assert.Contains(t, page, "<h3>12:00:00 to 12:00:01</h3>")
+	assert.Contains(t, page, "<h3>12:00:17 to 12:00:19</h3>")
+	assert.Contains(t, page, `<td>42</td><td class="text">ann</td><td class="text command" title="&lt;train&gt;">&lt;train&gt;</td><td>100.0%</td><td>2.0%</td><td>0.0%</td>`)
+	assert.Contains(t, page, `<td>7</td><td class="text">bob</td><td class="text command" title=""></td><td>0.0%</td><td>0.0%</td><td>80.0%</td>`)
+
+	// Nothing is loaded from elsewhere
+	assert.NotContains(t, page, "<script")
+	assert.NotContains(t, page, "src=")
+	assert.NotContains(t, page, "<link")
+}
@@ -0,0 +1,202 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jonsampson/mim/internal/store"
+)
+
+// Chart geometry in SVG user units; the charts scale to the page width
+const (
+	chartWidth  = 900
+	chartHeight = 200
+	marginLeft  = 44
+	marginRight = 10
+	marginTop   = 8
+	marginBot   = 22
+	plotWidth   = chartWidth - marginLeft - marginRight
+	plotHeight  = chartHeight - marginTop - marginBot
+	// chartPoints is the most points a line is drawn with; longer sessions
+	// are averaged down
+	chartPoints = 600
+	// heatmapColumns is the most time columns of the core heatmap
+	heatmapColumns = 200
+	// heatmapRowMin and heatmapRowMax bound the height of one core's row
+	heatmapRowMin = 2
+	heatmapRowMax = 12
+	timeTicks     = 5
+)
+
+// Series colors follow the TUI: CPU blue, memory magenta, GPUs from green
+const (
+	colorCPU    = "#3465a4"
+	colorMemory = "#a04ca0"
+)
+
+var gpuColors = []string{"#4e9a06", "#c4a000", "#06989a", "#cc0000", "#75507b", "#ce5c00"}
+
+// series is one line of a chart; value reports false for samples without
+// the metric, which leave a gap
+type series struct {
+	name  string
+	color string
+	value func(store.Sample) (float64, bool)
+}
+
+// lineChart draws percentages over time with a legend
+func lineChart(title string, samples []store.Sample, lines []series) template.HTML {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<figure><figcaption>%s</figcaption>`, html.EscapeString(title))
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" role="img" aria-label="%s">`, chartWidth, chartHeight, html.EscapeString(title))
+	axes(&b, samples[0].Time, samples[len(samples)-1].Time)
+
+	buckets := buckets(len(samples), chartPoints)
+	for _, line := range lines {
+		var segment []string
+		flush := func() {
+			if len(segment) > 0 {
+				fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="%s"/>`, line.color, strings.Join(segment, " "))
+			}
+			segment = nil
+		}
+		for _, bucket := range buckets {
+			sum, n := 0.0, 0
+			for _, s := range samples[bucket[0]:bucket[1]] {
+				if v, ok := line.value(s); ok {
+					sum += v
+					n++
+				}
+			}
+			if n == 0 {
+				flush()
+				continue
+			}
+			x := timeX(samples[bucket[0]].Time, samples[0].Time, samples[len(samples)-1].Time)
+			segment = append(segment, fmt.Sprintf("%.1f,%.1f", x, valueY(sum/float64(n))))
+		}
+		flush()
+	}
+	b.WriteString(`</svg><div class="legend">`)
+	for _, line := range lines {
+		fmt.Fprintf(&b, `<span><i style="background:%s"></i>%s</span>`, line.color, html.EscapeString(line.name))
+	}
+	b.WriteString(`</div></figure>`)
+	return template.HTML(b.String())
+}
+
+// heatmap draws every core's utilization over time, one row per core
+func heatmap(samples []store.Sample) template.HTML {
+	cores := 0
+	for _, s := range samples {
+		cores = max(cores, len(s.Cores))
+	}
+	if cores == 0 {
+		return ""
+	}
+	row := min(heatmapRowMax, max(heatmapRowMin, 240/cores))
+	height := cores*row + marginTop + marginBot
+	columns := buckets(len(samples), heatmapColumns)
+	width := float64(plotWidth) / float64(len(columns))
+
+	var b strings.Builder
+	b.WriteString(`<figure><figcaption>CPU cores</figcaption>`)
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" role="img" aria-label="CPU core heatmap">`, chartWidth, height)
+	for core := range cores {
+		if cores <= 16 || core%(cores/8) == 0 {
+			fmt.Fprintf(&b, `<text x="%d" y="%d" class="label" text-anchor="end" dominant-baseline="middle">%d</text>`,
+				marginLeft-4, marginTop+core*row+row/2, core)
+		}
+	}
+	for i, column := range columns {
+		for core := range cores {
+			sum, n := 0.0, 0
+			for _, s := range samples[column[0]:column[1]] {
+				if core < len(s.Cores) {
+					sum += s.Cores[core]
+					n++
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			// Cells overlap slightly so no seams show between them
+			fmt.Fprintf(&b, `<rect x="%.2f" y="%d" width="%.2f" height="%d" fill="%s"/>`,
+				marginLeft+float64(i)*width, marginTop+core*row, width+0.5, row, heatColor(sum/float64(n)))
+		}
+	}
+	timeLabels(&b, samples[0].Time, samples[len(samples)-1].Time, marginTop+cores*row)
+	b.WriteString(`</svg><div class="legend"><span><i style="background:` + heatColor(0) + `"></i>idle</span>` +
+		`<span><i style="background:` + heatColor(50) + `"></i>50%</span>` +
+		`<span><i style="background:` + heatColor(100) + `"></i>busy</span></div></figure>`)
+	return template.HTML(b.String())
+}
+
+// axes draws the percentage grid and the time labels of a line chart
+func axes(b *strings.Builder, start, end time.Time) {
+	for _, pct := range []float64{0, 25, 50, 75, 100} {
+		y := valueY(pct)
+		fmt.Fprintf(b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" class="grid"/>`, marginLeft, y, marginLeft+plotWidth, y)
+		fmt.Fprintf(b, `<text x="%d" y="%.1f" class="label" text-anchor="end" dominant-baseline="middle">%.0f%%</text>`, marginLeft-4, y, pct)
+	}
+	timeLabels(b, start, end, marginTop+plotHeight)
+}
+
+// timeLabels writes evenly spaced times below a plot whose bottom is at y
+func timeLabels(b *strings.Builder, start, end time.Time, y int) {
+	layout := time.TimeOnly
+	if end.Sub(start) > 24*time.Hour {
+		layout = "01-02 15:04"
+	}
+	for i := range timeTicks {
+		at := start.Add(end.Sub(start) * time.Duration(i) / (timeTicks - 1))
+		anchor := "middle"
+		switch i {
+		case 0:
+			anchor = "start"
+		case timeTicks - 1:
+			anchor = "end"
+		}
+		fmt.Fprintf(b, `<text x="%.1f" y="%d" class="label" text-anchor="%s">%s</text>`,
+			timeX(at, start, end), y+16, anchor, at.Format(layout))
+	}
+}
+
+// buckets splits n samples into at most limit consecutive index ranges
+func buckets(n, limit int) [][2]int {
+	count := min(n, limit)
+	result := make([][2]int, 0, count)
+	for i := range count {
+		result = append(result, [2]int{i * n / count, (i + 1) * n / count})
+	}
+	return result
+}
+
+func timeX(at, start, end time.Time) float64 {
+	if !end.After(start) {
+		return marginLeft
+	}
+	return marginLeft + float64(plotWidth)*float64(at.Sub(start))/float64(end.Sub(start))
+}
+
+func valueY(pct float64) float64 {
+	pct = math.Max(0, math.Min(100, pct))
+	return marginTop + float64(plotHeight)*(1-pct/100)
+}
+
+// heatColor shades from pale blue through yellow to red
+func heatColor(pct float64) string {
+	stops := [][3]float64{{232, 240, 248}, {245, 200, 60}, {200, 30, 30}}
+	t := math.Max(0, math.Min(100, pct)) / 50
+	i := min(int(t), 1)
+	f := t - float64(i)
+	var rgb [3]int
+	for c := range rgb {
+		rgb[c] = int(math.Round(stops[i][c] + (stops[i+1][c]-stops[i][c])*f))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+}
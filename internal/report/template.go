@@ -0,0 +1,54 @@
+package report
+
+import "html/template"
+
+// pageTemplate is the whole report; styles are inline so the file stands
+// alone
+var pageTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>mim report{{with .Host}}: {{.}}{{end}}</title>
+<style>
+body { font: 14px/1.4 system-ui, sans-serif; color: #222; max-width: 960px; margin: 2em auto; padding: 0 1em; }
+h1 { font-size: 1.5em; margin-bottom: 0.2em; }
+h2 { font-size: 1.2em; margin-top: 2em; border-bottom: 1px solid #ddd; }
+.meta { color: #666; }
+table { border-collapse: collapse; margin: 0.5em 0 1em; }
+th, td { padding: 2px 10px; text-align: right; }
+th { border-bottom: 1px solid #ccc; }
+td.text, th.text { text-align: left; }
+tr:nth-child(even) td { background: #f6f6f6; }
+figure { margin: 1em 0; }
+figcaption { font-weight: 600; }
+svg { width: 100%; height: auto; display: block; }
+svg .grid { stroke: #e4e4e4; stroke-width: 1; }
+svg .label { font-size: 11px; fill: #666; }
+.legend span { margin-right: 1.5em; color: #444; }
+.legend i { display: inline-block; width: 10px; height: 10px; margin-right: 4px; }
+.command { max-width: 32em; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+</style>
+</head>
+<body>
+<h1>mim report{{with .Host}}: {{.}}{{end}}</h1>
+<p class="meta">{{.Start}} to {{.End}} ({{.Duration}}), {{.Samples}} samples</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th class="text">metric</th><th>min</th><th>avg</th><th>p95</th><th>max</th></tr>
+{{range .Stats}}<tr><td class="text">{{.Name}}</td><td>{{printf "%.1f%%" .Min}}</td><td>{{printf "%.1f%%" .Avg}}</td><td>{{printf "%.1f%%" .P95}}</td><td>{{printf "%.1f%%" .Max}}</td></tr>
+{{end}}</table>
+
+<h2>Utilization</h2>
+{{range .Charts}}{{.}}
+{{end}}{{.Heatmap}}
+
+<h2>Top processes</h2>
+{{range .Intervals}}<h3>{{.From}} to {{.To}}</h3>
+<table>
+<tr><th>pid</th><th class="text">user</th><th class="text">command</th><th>cpu</th><th>mem</th>{{if $.GPU}}<th>gpu</th>{{end}}</tr>
+{{range .Processes}}<tr><td>{{.PID}}</td><td class="text">{{.User}}</td><td class="text command" title="{{.Command}}">{{.Command}}</td><td>{{printf "%.1f%%" .CPU}}</td><td>{{printf "%.1f%%" .Memory}}</td>{{if $.GPU}}<td>{{printf "%.1f%%" .GPU}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+// Stat summarizes one metric over a range of samples
+type Stat struct {
+	Name string
+	Min  float64
+	Avg  float64
+	P95  float64
+	Max  float64
+}
+
+// Summarize returns the statistics of total CPU, memory, and utilization and
+// memory of each GPU, in that order
+func Summarize(samples []Sample) []Stat {
+	if len(samples) == 0 {
+		return nil
+	}
+	series := []func(Sample) (float64, bool){
+		func(s Sample) (float64, bool) { return s.CPU, true },
+		func(s Sample) (float64, bool) { return s.Memory, true },
+	}
+	names := []string{"cpu", "memory"}
+	gpus := 0
+	for _, s := range samples {
+		gpus = max(gpus, len(s.GPUs))
+	}
+	for i := range gpus {
+		series = append(series,
+			func(s Sample) (float64, bool) {
+				if i < len(s.GPUs) {
+					return s.GPUs[i].Usage, true
+				}
+				return 0, false
+			},
+			func(s Sample) (float64, bool) {
+				if i < len(s.GPUs) {
+					return s.GPUs[i].Memory, true
+				}
+				return 0, false
+			},
+		)
+		names = append(names, fmt.Sprintf("gpu%d", i), fmt.Sprintf("gpu%d memory", i))
+	}
+
+	stats := make([]Stat, 0, len(series))
+	for i, value := range series {
+		var values []float64
+		for _, s := range samples {
+			if v, ok := value(s); ok {
+				values = append(values, v)
+			}
+		}
+		stats = append(stats, summarize(names[i], values))
+	}
+	return stats
+}
+
+func summarize(name string, values []float64) Stat {
+	stats := domain.NewStats()
+	for _, v := range values {
+		stats.Add(v)
+	}
+	return Stat{Name: name, Min: stats.Min(), Avg: stats.Mean(), P95: stats.Percentile(95), Max: stats.Max()}
+}
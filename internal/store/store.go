@@ -0,0 +1,616 @@
+// Package store keeps the metrics of long-running sessions on disk.
+//
+// Samples are kept in tiers of decreasing resolution: every second for an
+// hour, 10 second averages for a day and minute averages for a month. Each
+// tier is a directory of append-only segment files named after the Unix time
+// their span starts. Records are length-prefixed, so a segment that is being
+// written can be read at the same time, and a segment is deleted whole once
+// its span falls out of the tier's retention. One process writes a store at
+// a time, holding a lock on it while open.
+package store
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/jonsampson/mim/internal/export"
+)
+
+// segmentExt is the file extension of segment files
+const segmentExt = ".seg"
+
+// lockName is the file a writing store holds a lock on, so two instances
+// never append to the same segments
+const lockName = "lock"
+
+// maxRecordSize is the largest record the format allows: every core and GPU
+// count at its limit
+const maxRecordSize = 12 + 4*(2+3*math.MaxUint16)
+
+// ErrLocked is returned by Open when another process writes to the store
+var ErrLocked = errors.New("store is in use by another mim")
+
+// Tier is one resolution of the store
+type Tier struct {
+	Name      string
+	Step      time.Duration
+	Retention time.Duration
+	// Span is the time covered by one segment file
+	Span time.Duration
+}
+
+// Tiers are ordered from the finest to the coarsest
+var Tiers = []Tier{
+	{Name: "1s", Step: time.Second, Retention: time.Hour, Span: 10 * time.Minute},
+	{Name: "10s", Step: 10 * time.Second, Retention: 24 * time.Hour, Span: 2 * time.Hour},
+	{Name: "1m", Step: time.Minute, Retention: 30 * 24 * time.Hour, Span: 24 * time.Hour},
+}
+
+// GPU is the utilization and memory of one GPU
+type GPU struct {
+	Usage  float64
+	Memory float64
+}
+
+// Sample is the host-wide metrics at one instant, or their average over a
+// tier's step
+type Sample struct {
+	Time   time.Time
+	CPU    float64
+	Cores  []float64
+	Memory float64
+	GPUs   []GPU
+}
+
+// CPUMemory returns the sample as the CPU collector reports it
+func (s Sample) CPUMemory() domain.CPUMemoryMetrics {
+	return domain.CPUMemoryMetrics{CPUUsageTotal: s.CPU, CPUUsagePerCore: s.Cores, MemoryUsage: s.Memory}
+}
+
+// GPUMetrics returns the sample as the GPU collector reports it; false when
+// it has no GPUs. As with the collector, the totals are those of GPU 0.
+func (s Sample) GPUMetrics() (domain.GPUMetrics, bool) {
+	if len(s.GPUs) == 0 {
+		return domain.GPUMetrics{}, false
+	}
+	metrics := domain.GPUMetrics{GPUUsage: s.GPUs[0].Usage, GPUMemoryUsage: s.GPUs[0].Memory}
+	for i, gpu := range s.GPUs {
+		metrics.Devices = append(metrics.Devices, domain.GPUDeviceMetrics{Index: i, Usage: gpu.Usage, MemoryUsage: gpu.Memory})
+	}
+	return metrics, true
+}
+
+// FromSnapshot picks the host-wide points out of an export snapshot; false
+// when it has none
+func FromSnapshot(snapshot export.Snapshot) (Sample, bool) {
+	sample := Sample{Time: snapshot.Time}
+	found := false
+	for _, point := range snapshot.Points {
+		switch point.Name {
+		case export.MetricCPU:
+			sample.CPU = point.Value
+		case export.MetricMemory:
+			sample.Memory = point.Value
+		case export.MetricCPUCore:
+			i, err := strconv.Atoi(point.Attributes["cpu"])
+			if err != nil || i < 0 {
+				continue
+			}
+			for len(sample.Cores) <= i {
+				sample.Cores = append(sample.Cores, 0)
+			}
+			sample.Cores[i] = point.Value
+		case export.MetricGPU, export.MetricGPUMemory:
+			i, err := strconv.Atoi(point.Attributes["gpu"])
+			if err != nil || i < 0 {
+				continue
+			}
+			for len(sample.GPUs) <= i {
+				sample.GPUs = append(sample.GPUs, GPU{})
+			}
+			if point.Name == export.MetricGPU {
+				sample.GPUs[i].Usage = point.Value
+			} else {
+				sample.GPUs[i].Memory = point.Value
+			}
+		default:
+			continue
+		}
+		found = true
+	}
+	return sample, found
+}
+
+// DefaultDir is where the store lives unless configured, under the XDG
+// state directory
+func DefaultDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "mim", "store")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "state", "mim", "store")
+}
+
+// Store appends samples to every tier. It is an export.Sink, so an Exporter
+// with a one second interval keeps it written in the background.
+type Store struct {
+	mu    sync.Mutex
+	lock  *os.File
+	tiers []*tierWriter
+}
+
+// Open creates the store's directories under dir if needed and locks the
+// store until Close; a store already open elsewhere returns ErrLocked
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	lock, err := os.OpenFile(filepath.Join(dir, lockName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		lock.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("%s: %w", dir, ErrLocked)
+		}
+		return nil, err
+	}
+
+	s := &Store{lock: lock}
+	for _, tier := range Tiers {
+		path := filepath.Join(dir, tier.Name)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			lock.Close()
+			return nil, err
+		}
+		s.tiers = append(s.tiers, &tierWriter{Tier: tier, dir: path})
+	}
+	return s, nil
+}
+
+// Append writes a sample to the finest tier and adds it to the averages of
+// the others, which are written once their step is complete
+func (s *Store) Append(sample Sample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, tier := range s.tiers {
+		if i == 0 {
+			if err := tier.write(sample); err != nil {
+				return err
+			}
+			continue
+		}
+		if average, ok := tier.add(sample); ok {
+			if err := tier.write(average); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Store) Export(_ context.Context, snapshot export.Snapshot) error {
+	sample, ok := FromSnapshot(snapshot)
+	if !ok {
+		return nil
+	}
+	return s.Append(sample)
+}
+
+// Close closes the open segments and releases the lock. Averages of
+// incomplete steps are dropped.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for _, tier := range s.tiers {
+		if tier.file != nil {
+			errs = append(errs, tier.file.Close())
+			tier.file = nil
+		}
+	}
+	if s.lock != nil {
+		errs = append(errs, s.lock.Close())
+		s.lock = nil
+	}
+	return errors.Join(errs...)
+}
+
+// tierWriter appends to the open segment of one tier and, for downsampled
+// tiers, averages the samples of the current step
+type tierWriter struct {
+	Tier
+	dir     string
+	file    *os.File
+	segment time.Time
+	step    time.Time
+	sum     accumulator
+}
+
+// add accumulates a sample and returns the average of the previous step
+// once the sample starts a new one
+func (t *tierWriter) add(sample Sample) (Sample, bool) {
+	step := sample.Time.Truncate(t.Step)
+	var average Sample
+	done := t.sum.n > 0 && !step.Equal(t.step)
+	if done {
+		average = t.sum.average(t.step)
+		t.sum = accumulator{}
+	}
+	t.step = step
+	t.sum.add(sample)
+	return average, done
+}
+
+// write appends a record, moving to a new segment, and pruning expired
+// ones, when the sample is past the open segment's span
+func (t *tierWriter) write(sample Sample) error {
+	segment := sample.Time.Truncate(t.Span)
+	if t.file == nil || !segment.Equal(t.segment) {
+		if t.file != nil {
+			t.file.Close()
+		}
+		file, err := openSegment(segmentPath(t.dir, segment))
+		if err != nil {
+			t.file = nil
+			return err
+		}
+		t.file, t.segment = file, segment
+		if err := prune(t.dir, t.Tier, sample.Time); err != nil {
+			return err
+		}
+	}
+	_, err := t.file.Write(encode(sample))
+	return err
+}
+
+// openSegment opens a segment for appending. A record left torn by a
+// previous run that stopped mid-write is cut off, so new records follow the
+// last whole one instead of being read as part of it.
+func openSegment(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	whole, err := wholeRecords(bufio.NewReader(file))
+	if err == nil {
+		err = file.Truncate(whole)
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return file, nil
+}
+
+// wholeRecords returns the length of the records at the start of r that
+// decode
+func wholeRecords(r io.Reader) (int64, error) {
+	var n int64
+	for {
+		record, err := readRecord(r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF || errors.Is(err, errCorrupt) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if _, err := parseRecord(record); err != nil {
+			return n, nil
+		}
+		n += int64(4 + len(record))
+	}
+}
+
+// accumulator sums samples to average them; cores and GPUs are averaged
+// over the samples that have them
+type accumulator struct {
+	n      int
+	cpu    float64
+	memory float64
+	cores  mean
+	gpus   mean // usage and memory interleaved
+}
+
+func (a *accumulator) add(s Sample) {
+	a.n++
+	a.cpu += s.CPU
+	a.memory += s.Memory
+	a.cores.add(s.Cores)
+	gpus := make([]float64, 0, 2*len(s.GPUs))
+	for _, gpu := range s.GPUs {
+		gpus = append(gpus, gpu.Usage, gpu.Memory)
+	}
+	a.gpus.add(gpus)
+}
+
+func (a *accumulator) average(at time.Time) Sample {
+	sample := Sample{
+		Time:   at,
+		CPU:    a.cpu / float64(a.n),
+		Memory: a.memory / float64(a.n),
+		Cores:  a.cores.values(),
+	}
+	gpus := a.gpus.values()
+	for i := 0; i+1 < len(gpus); i += 2 {
+		sample.GPUs = append(sample.GPUs, GPU{Usage: gpus[i], Memory: gpus[i+1]})
+	}
+	return sample
+}
+
+// mean averages slices element by element
+type mean struct {
+	sum []float64
+	n   []int
+}
+
+func (m *mean) add(values []float64) {
+	for i, v := range values {
+		if i == len(m.sum) {
+			m.sum = append(m.sum, 0)
+			m.n = append(m.n, 0)
+		}
+		m.sum[i] += v
+		m.n[i]++
+	}
+}
+
+func (m *mean) values() []float64 {
+	if len(m.sum) == 0 {
+		return nil
+	}
+	values := make([]float64, len(m.sum))
+	for i := range values {
+		values[i] = m.sum[i] / float64(m.n[i])
+	}
+	return values
+}
+
+// segments lists a tier's segment start times, oldest first
+func segments(dir string) ([]time.Time, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var starts []time.Time
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), segmentExt)
+		if !ok {
+			continue
+		}
+		if unix, err := strconv.ParseInt(name, 10, 64); err == nil {
+			starts = append(starts, time.Unix(unix, 0))
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	return starts, nil
+}
+
+func segmentPath(dir string, start time.Time) string {
+	return filepath.Join(dir, strconv.FormatInt(start.Unix(), 10)+segmentExt)
+}
+
+// prune deletes the segments whose whole span is older than the retention
+func prune(dir string, tier Tier, now time.Time) error {
+	starts, err := segments(dir)
+	if err != nil {
+		return err
+	}
+	cutoff := now.Add(-tier.Retention)
+	for _, start := range starts {
+		if start.Add(tier.Span).Before(cutoff) {
+			if err := os.Remove(segmentPath(dir, start)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Query reads the samples from..to of the store in dir and returns the tier
+// they come from: the finest one that reaches back to from, or to the oldest
+// sample stored when from is earlier than that
+func Query(dir string, from, to time.Time) ([]Sample, Tier, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, Tier{}, fmt.Errorf("no store at %s: %w", dir, err)
+	}
+	firsts := make([]time.Time, len(Tiers))
+	var oldest time.Time
+	for i, tier := range Tiers {
+		first, err := firstSample(filepath.Join(dir, tier.Name))
+		if err != nil {
+			return nil, Tier{}, err
+		}
+		firsts[i] = first
+		if !first.IsZero() && (oldest.IsZero() || first.Before(oldest)) {
+			oldest = first
+		}
+	}
+	reach := from
+	if oldest.After(reach) {
+		reach = oldest
+	}
+	// Averages are stamped with the start of their step, up to a step before
+	// the first sample they include
+	slack := Tiers[len(Tiers)-1].Step
+	tier := Tiers[0]
+	for i, candidate := range Tiers {
+		if !firsts[i].IsZero() && firsts[i].Before(reach.Add(slack)) {
+			tier = candidate
+			break
+		}
+	}
+
+	tierDir := filepath.Join(dir, tier.Name)
+	starts, err := segments(tierDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, Tier{}, err
+	}
+	var samples []Sample
+	for _, start := range starts {
+		if !start.Add(tier.Span).After(from) || start.After(to) {
+			continue
+		}
+		read, err := readSegment(segmentPath(tierDir, start))
+		if err != nil {
+			return nil, Tier{}, err
+		}
+		for _, sample := range read {
+			if !sample.Time.Before(from) && !sample.Time.After(to) {
+				samples = append(samples, sample)
+			}
+		}
+	}
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].Time.Before(samples[j].Time) })
+	return samples, tier, nil
+}
+
+// firstSample returns the time of a tier's oldest sample; zero when it has
+// none
+func firstSample(dir string) (time.Time, error) {
+	starts, err := segments(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return time.Time{}, err
+	}
+	for _, start := range starts {
+		samples, err := readSegment(segmentPath(dir, start))
+		if err != nil {
+			return time.Time{}, err
+		}
+		if len(samples) > 0 {
+			return samples[0].Time, nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+// readSegment decodes a segment; a record cut short by a write in progress
+// ends it, and so does a corrupt one, as when the segment is next opened
+// for writing
+func readSegment(path string) ([]Sample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	r := bufio.NewReader(file)
+	var samples []Sample
+	for {
+		sample, err := decode(r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return samples, nil
+		}
+		if errors.Is(err, errCorrupt) {
+			log.Printf("store: %s: %v after %d samples; ignoring the rest", path, err, len(samples))
+			return samples, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		samples = append(samples, sample)
+	}
+}
+
+// A record is its length, then the time in Unix milliseconds, the core and
+// GPU counts, and the values as float32: CPU, memory, every core, and usage
+// and memory of every GPU. All little endian.
+func encode(s Sample) []byte {
+	values := 2 + len(s.Cores) + 2*len(s.GPUs)
+	buf := make([]byte, 4, 4+12+4*values)
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(s.Time.UnixMilli()))
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(s.Cores)))
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(s.GPUs)))
+	appendValue := func(v float64) {
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(v)))
+	}
+	appendValue(s.CPU)
+	appendValue(s.Memory)
+	for _, core := range s.Cores {
+		appendValue(core)
+	}
+	for _, gpu := range s.GPUs {
+		appendValue(gpu.Usage)
+		appendValue(gpu.Memory)
+	}
+	binary.LittleEndian.PutUint32(buf, uint32(len(buf)-4))
+	return buf
+}
+
+// errCorrupt is returned for a record that cannot be decoded
+var errCorrupt = errors.New("corrupt record")
+
+func decode(r io.Reader) (Sample, error) {
+	record, err := readRecord(r)
+	if err != nil {
+		return Sample{}, err
+	}
+	return parseRecord(record)
+}
+
+// readRecord reads one length-prefixed record, refusing lengths the format
+// cannot produce before allocating for them
+func readRecord(r io.Reader) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	length := binary.LittleEndian.Uint32(size[:])
+	if length < 12 || length > maxRecordSize {
+		return nil, errCorrupt
+	}
+	record := make([]byte, length)
+	if _, err := io.ReadFull(r, record); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+func parseRecord(record []byte) (Sample, error) {
+	cores := int(binary.LittleEndian.Uint16(record[8:]))
+	gpus := int(binary.LittleEndian.Uint16(record[10:]))
+	if len(record) != 12+4*(2+cores+2*gpus) {
+		return Sample{}, errCorrupt
+	}
+	values := record[12:]
+	next := func() float64 {
+		v := math.Float32frombits(binary.LittleEndian.Uint32(values))
+		values = values[4:]
+		return float64(v)
+	}
+
+	s := Sample{Time: time.UnixMilli(int64(binary.LittleEndian.Uint64(record)))}
+	s.CPU = next()
+	s.Memory = next()
+	if cores > 0 {
+		s.Cores = make([]float64, cores)
+		for i := range s.Cores {
+			s.Cores[i] = next()
+		}
+	}
+	for range gpus {
+		s.GPUs = append(s.GPUs, GPU{Usage: next(), Memory: next()})
+	}
+	return s, nil
+}
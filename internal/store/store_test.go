@@ -0,0 +1,209 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/jonsampson/mim/internal/export"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreTiers(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	require.NoError(t, err)
+
+	// Two and a half minutes of samples; CPU counts the seconds of each minute
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 150 {
+		at := start.Add(time.Duration(i) * time.Second)
+		sample := Sample{Time: at, CPU: float64(i % 60), Memory: 50, Cores: []float64{float64(i % 60)}}
+		if i >= 60 {
+			sample.GPUs = []GPU{{Usage: 80, Memory: 10}}
+		}
+		require.NoError(t, s.Append(sample))
+	}
+
+	samples, tier, err := Query(dir, start, start.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "1s", tier.Name)
+	require.Len(t, samples, 61)
+	assert.True(t, start.Add(time.Minute).Equal(samples[60].Time))
+	assert.Equal(t, []GPU{{Usage: 80, Memory: 10}}, samples[60].GPUs)
+
+	// The 10s and 1m tiers hold the averages of their completed steps
+	samples, err = readSegment(segmentPath(filepath.Join(dir, "10s"), start))
+	require.NoError(t, err)
+	require.Len(t, samples, 14)
+	assert.Equal(t, 4.5, samples[0].CPU)
+	assert.Equal(t, []float64{14.5}, samples[1].Cores)
+	samples, err = readSegment(segmentPath(filepath.Join(dir, "1m"), start.Truncate(24*time.Hour)))
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, Sample{Time: start, CPU: 29.5, Memory: 50, Cores: []float64{29.5}}, utcSample(samples[0]))
+	assert.Equal(t, []GPU{{Usage: 80, Memory: 10}}, samples[1].GPUs)
+
+	// The finest tier has everything since the store started
+	samples, tier, err = Query(dir, start.Add(-time.Hour), start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "1s", tier.Name)
+	assert.Len(t, samples, 150)
+	require.NoError(t, s.Close())
+
+	samples, err = readSegment(segmentPath(filepath.Join(dir, "10s"), start))
+	require.NoError(t, err)
+	stats := Summarize(samples)
+	assert.Equal(t, []string{"cpu", "memory", "gpu0", "gpu0 memory"}, []string{stats[0].Name, stats[1].Name, stats[2].Name, stats[3].Name})
+	assert.Equal(t, Stat{Name: "cpu", Min: 4.5, Avg: 373.0 / 14, P95: 54.5, Max: 54.5}, stats[0])
+	assert.Equal(t, Stat{Name: "gpu0", Min: 80, Avg: 80, P95: 80, Max: 80}, stats[2])
+}
+
+func TestStoreRetention(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	require.NoError(t, err)
+	defer s.Close()
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, s.Append(Sample{Time: start, CPU: 1}))
+	require.NoError(t, s.Append(Sample{Time: start.Add(2 * time.Hour), CPU: 2}))
+
+	starts, err := segments(filepath.Join(dir, "1s"))
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{start.Add(2 * time.Hour)}, utc(starts))
+
+	// What the finest tier dropped is read from a coarser one
+	samples, tier, err := Query(dir, start, start.Add(3*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "10s", tier.Name)
+	require.Len(t, samples, 1)
+	assert.Equal(t, 1.0, samples[0].CPU)
+}
+
+func TestStoreTruncatedRecord(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	require.NoError(t, err)
+
+	// The store is written by an exporter, as a sink
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	exporter := export.NewExporter("box", time.Second, s)
+	exporter.Update(domain.CPUMemoryMetrics{CPUUsageTotal: 25, CPUUsagePerCore: []float64{20, 30}, MemoryUsage: 40})
+	exporter.Update(domain.GPUMetrics{Devices: []domain.GPUDeviceMetrics{{Index: 0, Usage: 70, MemoryUsage: 5}, {Index: 1, Usage: 90}}})
+	require.NoError(t, s.Export(context.Background(), exporter.Snapshot(start)))
+	require.NoError(t, s.Export(context.Background(), exporter.Snapshot(start.Add(time.Second))))
+	require.NoError(t, s.Close())
+
+	// A record being written is not read
+	path := segmentPath(filepath.Join(dir, "1s"), start)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-3))
+
+	samples, _, err := Query(dir, start, start.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, Sample{
+		Time:   start,
+		CPU:    25,
+		Cores:  []float64{20, 30},
+		Memory: 40,
+		GPUs:   []GPU{{Usage: 70, Memory: 5}, {Usage: 90}},
+	}, utcSample(samples[0]))
+	gpu, ok := samples[0].GPUMetrics()
+	assert.True(t, ok)
+	assert.Equal(t, 70.0, gpu.GPUUsage)
+	assert.Len(t, gpu.Devices, 2)
+}
+
+func TestStoreAppendAfterTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	require.NoError(t, err)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, s.Append(Sample{Time: start, CPU: 1}))
+	require.NoError(t, s.Close())
+
+	// A run stopped partway through its second record
+	path := segmentPath(filepath.Join(dir, "1s"), start)
+	torn := encode(Sample{Time: start.Add(time.Second), CPU: 2, Cores: []float64{2, 2}})
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = file.Write(torn[:len(torn)-5])
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	// The next run appends after the last whole record
+	s, err = Open(dir)
+	require.NoError(t, err)
+	require.NoError(t, s.Append(Sample{Time: start.Add(2 * time.Second), CPU: 3}))
+	require.NoError(t, s.Close())
+
+	samples, err := readSegment(path)
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, 1.0, samples[0].CPU)
+	assert.Equal(t, 3.0, samples[1].CPU)
+}
+
+func TestStoreLock(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	require.NoError(t, err)
+
+	_, err = Open(dir)
+	assert.ErrorIs(t, err, ErrLocked)
+
+	require.NoError(t, s.Close())
+	s, err = Open(dir)
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+}
+
+func TestDecodeOversizedRecord(t *testing.T) {
+	record := []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}
+	_, err := decode(bytes.NewReader(record))
+	assert.ErrorIs(t, err, errCorrupt)
+}
+
+func utc(times []time.Time) []time.Time {
+	for i := range times {
+		times[i] = times[i].UTC()
+	}
+	return times
+}
+
+func utcSample(s Sample) Sample {
+	s.Time = s.Time.UTC()
+	return s
+}
+
+func TestQueryCorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	require.NoError(t, err)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, s.Append(Sample{Time: start, CPU: 1}))
+	require.NoError(t, s.Close())
+
+	// A record whose core count disagrees with its length, then a whole one
+	path := segmentPath(filepath.Join(dir, "1s"), start)
+	corrupt := encode(Sample{Time: start.Add(time.Second), CPU: 2})
+	corrupt[4+8] = 1
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = file.Write(append(corrupt, encode(Sample{Time: start.Add(2 * time.Second), CPU: 3})...))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	samples, _, err := Query(dir, start, start.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, 1.0, samples[0].CPU)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"sort"
 	"strings"
 
 	"github.com/NimbleMarkets/ntcharts/sparkline"
@@ -13,7 +14,12 @@ import (
 
 type BusiestCores struct {
 	coreUsages      []float64
+	groups          []domain.TopologyGroup // cores by socket and NUMA node
+	grouper         *domain.TopologyGrouper
 	coreCharts      map[int]*sparkline.Model
+	freqCharts      map[int]*sparkline.Model
+	frequencies     map[int]domain.CoreFrequency
+	showFrequency   bool
 	width           int
 	height          int
 	squareDimension int
@@ -29,6 +35,9 @@ func NewBusiestCores() *BusiestCores {
 	return &BusiestCores{
 		coreUsages:  make([]float64, 0),
 		coreCharts:  make(map[int]*sparkline.Model),
+		freqCharts:  make(map[int]*sparkline.Model),
+		frequencies: make(map[int]domain.CoreFrequency),
+		grouper:     domain.NewTopologyGrouper(),
 		// Initialize cached styles once
 		labelStyle:  lipgloss.NewStyle().Foreground(lipgloss.Color("4")),
 		lowStyle:    lipgloss.NewStyle().Foreground(lipgloss.Color("6")),    // Cyan
@@ -39,13 +48,28 @@ func NewBusiestCores() *BusiestCores {
 
 func (b *BusiestCores) initializeIfNeeded(coreID int) {
 	if b.coreCharts[coreID] == nil {
-		graphWidth := max((b.width / (b.squareDimension * 2)), 1)
-		chart := sparkline.New(graphWidth, 1,
-			sparkline.WithMaxValue(100),
-		)
-		chart.PushAll(make([]float64, graphWidth))
-		b.coreCharts[coreID] = &chart
+		b.coreCharts[coreID] = b.newChart()
 	}
+	if b.freqCharts[coreID] == nil {
+		b.freqCharts[coreID] = b.newChart()
+	}
+}
+
+func (b *BusiestCores) newChart() *sparkline.Model {
+	graphWidth := max((b.width / (b.squareDimension * 2)), 1)
+	chart := sparkline.New(graphWidth, 1,
+		sparkline.WithMaxValue(100),
+	)
+	chart.PushAll(make([]float64, graphWidth))
+	return &chart
+}
+
+// ToggleFrequency switches the per-core graphs between usage and clock speed
+func (b *BusiestCores) ToggleFrequency() {
+	if b == nil {
+		return
+	}
+	b.showFrequency = !b.showFrequency
 }
 
 // Update handles incoming messages and updates the internal state
@@ -62,10 +86,15 @@ func (b *BusiestCores) updateMetrics(metrics domain.CPUMemoryMetrics) {
 		return
 	}
 	b.coreUsages = metrics.CPUUsagePerCore
+	b.groups = b.grouper.Group(metrics.Topology, len(b.coreUsages))
 	b.squareDimension = int(math.Ceil(math.Sqrt(float64(len(b.coreUsages)))))
+	for _, freq := range metrics.CoreFrequencies {
+		b.frequencies[freq.CPU] = freq
+	}
 	for i, usage := range metrics.CPUUsagePerCore {
 		b.initializeIfNeeded(i)
 		b.coreCharts[i].Push(usage)
+		b.freqCharts[i].Push(frequencyPercent(b.frequencies[i]))
 	}
 }
 
@@ -76,22 +105,37 @@ func (b *BusiestCores) View() string {
 	}
 
 	var views []string
-	for row := 0; row < b.squareDimension; row++ {
-		var rowViews []string
-		for col := 0; col < b.squareDimension; col++ {
-			coreID := row*b.squareDimension + col
-			if coreID >= len(b.coreUsages) {
-				break
+	if b.showFrequency {
+		views = append(views, b.frequencyHeader())
+	}
+	for _, group := range b.groups {
+		// Each socket/NUMA node starts a new row under its label
+		if len(b.groups) > 1 {
+			views = append(views, b.groupSeparator(group))
+		}
+		for start := 0; start < len(group.CPUs); start += b.squareDimension {
+			var rowViews []string
+			for _, coreID := range group.CPUs[start:min(start+b.squareDimension, len(group.CPUs))] {
+				rowViews = append(rowViews, b.renderCore(coreID))
 			}
-			rowViews = append(rowViews, b.renderCore(coreID))
+			views = append(views, strings.Join(rowViews, " "))
 		}
-		views = append(views, strings.Join(rowViews, " "))
 	}
 
 	return strings.Join(views, "\n")
 }
 
+// groupSeparator renders a rule labelled with the group's socket and node
+func (b *BusiestCores) groupSeparator(group domain.TopologyGroup) string {
+	label := "── " + group.Label() + " "
+	return b.labelStyle.Render(label + strings.Repeat("─", max(b.width-lipgloss.Width(label), 0)))
+}
+
 func (b *BusiestCores) renderCore(coreID int) string {
+	if b.showFrequency {
+		return b.renderCoreFrequency(coreID)
+	}
+
 	chart := b.coreCharts[coreID]
 	chart.DrawBraille()
 	usage := b.coreUsages[coreID]
@@ -115,6 +159,72 @@ func (b *BusiestCores) renderCore(coreID int) string {
 	)
 }
 
+// renderCoreFrequency renders clock speed history for a core. Cores that are
+// busy but running well below their policy maximum are highlighted, as are
+// cores pinned at a policy maximum below what the hardware allows
+// (boost-limited).
+func (b *BusiestCores) renderCoreFrequency(coreID int) string {
+	chart := b.freqCharts[coreID]
+	chart.DrawBraille()
+	freq := b.frequencies[coreID]
+	ratio := frequencyPercent(freq)
+
+	style := b.lowStyle
+	if b.coreUsages[coreID] > 50 && ratio < 60 {
+		style = b.highStyle
+	} else if boostLimited(freq) {
+		style = b.mediumStyle
+	}
+
+	coreLabel := b.labelStyle.Render(fmt.Sprintf("@%2d", coreID))
+
+	return fmt.Sprintf("%s %s %4.1fG",
+		coreLabel,
+		style.Render(chart.View()),
+		freq.CurrentMHz/1000,
+	)
+}
+
+// frequencyHeader summarizes governors and limits across cores
+func (b *BusiestCores) frequencyHeader() string {
+	if len(b.frequencies) == 0 {
+		return b.labelStyle.Render("Frequency: cpufreq not available")
+	}
+
+	governors := make(map[string]int)
+	var minMHz, maxMHz float64
+	for _, freq := range b.frequencies {
+		governors[freq.Governor]++
+		if minMHz == 0 || freq.MinMHz < minMHz {
+			minMHz = freq.MinMHz
+		}
+		maxMHz = math.Max(maxMHz, freq.MaxMHz)
+	}
+	names := make([]string, 0, len(governors))
+	for name := range governors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return b.labelStyle.Render(fmt.Sprintf("Frequency  governor: %s  limits: %.1f-%.1f GHz",
+		strings.Join(names, ","), minMHz/1000, maxMHz/1000))
+}
+
+// frequencyPercent returns the current frequency as a percentage of the
+// policy maximum, or 0 when unknown
+func frequencyPercent(freq domain.CoreFrequency) float64 {
+	if freq.MaxMHz <= 0 {
+		return 0
+	}
+	return math.Min(freq.CurrentMHz/freq.MaxMHz*100, 100)
+}
+
+// boostLimited reports whether a core runs at its policy maximum while the
+// hardware could clock it higher
+func boostLimited(freq domain.CoreFrequency) bool {
+	return frequencyPercent(freq) >= 99 && freq.HardwareMax > freq.MaxMHz
+}
+
 // Resize adjusts the size of the braille graphs based on available space.
 func (b *BusiestCores) Resize(width, height int) {
 	if b == nil {
@@ -130,6 +240,9 @@ func (b *BusiestCores) Resize(width, height int) {
 	for _, chart := range b.coreCharts {
 		chart.Resize(graphWidth, 1)
 	}
+	for _, chart := range b.freqCharts {
+		chart.Resize(graphWidth, 1)
+	}
 }
 
 // max returns the larger of x or y
@@ -0,0 +1,361 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+const (
+	// hostTimeout is how long a host may stay silent before it is shown as
+	// offline
+	hostTimeout = 5 * time.Second
+
+	clusterHostWidth      = 18
+	clusterStatusWidth    = 8
+	clusterSparklineWidth = 10
+	clusterHeaderHeight   = 2 // title and column headings
+)
+
+var offlineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+// ClusterHost is one row of the cluster overview. Summary plugins feed the
+// row; Plugins run when the host is opened in the single-host view.
+type ClusterHost struct {
+	Name    string
+	Summary []Plugin
+	Plugins []Plugin
+}
+
+// clusterHost is the running state of a row
+type clusterHost struct {
+	ClusterHost
+	sources  map[string]Source
+	running  int // sources that have not ended
+	cpu      domain.CPUMemoryMetrics
+	gpus     []*compactLine
+	lastSeen time.Time
+}
+
+// clusterMetricsMsg carries a summary value of one host
+type clusterMetricsMsg struct {
+	host   int
+	source string
+	value  any
+}
+
+// clusterEndedMsg reports that a summary source closed, for example at the
+// end of a recording
+type clusterEndedMsg struct {
+	host   int
+	source string
+}
+
+type clusterTickMsg time.Time
+
+// hostMsg carries a message for the opened host. Messages from a host that
+// has since been closed carry an old generation and are dropped.
+type hostMsg struct {
+	generation int
+	msg        tea.Msg
+}
+
+// ClusterModel lists several hosts with their CPU, memory, GPU and busiest
+// process, and opens the selected host in the single-host view
+type ClusterModel struct {
+	hosts      []*clusterHost
+	selected   int
+	width      int
+	height     int
+	keys       KeyMap
+	help       help.Model
+	configure  func(*Model) // applies settings to an opened host
+	host       *Model       // the opened host, nil on the overview
+	generation int
+	err        string
+}
+
+// NewClusterModel starts the summary sources of every host. configure, if
+// not nil, is applied to each host view as it is opened.
+func NewClusterModel(hosts []ClusterHost, configure func(*Model)) (ClusterModel, error) {
+	if len(hosts) == 0 {
+		return ClusterModel{}, fmt.Errorf("no hosts provided")
+	}
+
+	m := ClusterModel{
+		keys:      DefaultKeyMap(),
+		help:      help.New(),
+		configure: configure,
+		width:     80,
+		height:    24,
+	}
+	for _, host := range hosts {
+		h := &clusterHost{ClusterHost: host, sources: make(map[string]Source)}
+		for _, plugin := range host.Summary {
+			source := plugin.NewSource()
+			h.sources[plugin.Name] = source
+			source.Start()
+		}
+		h.running = len(h.sources)
+		m.hosts = append(m.hosts, h)
+	}
+	return m, nil
+}
+
+// SetKeyMap replaces the key bindings of the overview and of opened hosts
+func (m *ClusterModel) SetKeyMap(keys KeyMap) {
+	m.keys = keys
+}
+
+func (m ClusterModel) Init() tea.Cmd {
+	var cmds []tea.Cmd
+	for i, h := range m.hosts {
+		for _, plugin := range h.Summary {
+			cmds = append(cmds, m.listen(i, plugin.Name))
+		}
+	}
+	cmds = append(cmds, clusterTick(), tea.EnterAltScreen)
+	return tea.Batch(cmds...)
+}
+
+// listen waits for the next summary value of a host
+func (m ClusterModel) listen(host int, name string) tea.Cmd {
+	next := m.hosts[host].sources[name].listen(name)
+	return func() tea.Msg {
+		msg, ok := next().(metricsMsg)
+		if !ok {
+			return clusterEndedMsg{host: host, source: name}
+		}
+		return clusterMetricsMsg{host: host, source: name, value: msg.value}
+	}
+}
+
+// clusterTick re-renders the overview so silent hosts turn offline
+func clusterTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return clusterTickMsg(t) })
+}
+
+func (m ClusterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m.forward(msg)
+
+	case tea.MouseMsg:
+		return m.forward(msg)
+
+	case clusterMetricsMsg:
+		h := m.hosts[msg.host]
+		h.update(msg.value)
+		h.lastSeen = time.Now()
+		if _, ok := h.sources[msg.source]; ok {
+			return m, m.listen(msg.host, msg.source)
+		}
+
+	case clusterEndedMsg:
+		m.hosts[msg.host].running--
+
+	case clusterTickMsg:
+		return m, clusterTick()
+
+	case hostMsg:
+		if msg.generation == m.generation {
+			return m.forward(msg.msg)
+		}
+	}
+	return m, nil
+}
+
+func (m ClusterModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keys.Quit) {
+		if m.host != nil {
+			m.host.stop()
+		}
+		for _, h := range m.hosts {
+			for _, source := range h.sources {
+				source.Stop()
+			}
+		}
+		return m, tea.Quit
+	}
+
+	if m.host != nil {
+		if key.Matches(msg, m.keys.Back) {
+			m.host.stop()
+			m.host = nil
+			return m, nil
+		}
+		return m.forward(msg)
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.selected = max(m.selected-1, 0)
+	case key.Matches(msg, m.keys.Down):
+		m.selected = min(m.selected+1, len(m.hosts)-1)
+	case key.Matches(msg, m.keys.Open):
+		return m.open(m.selected)
+	}
+	return m, nil
+}
+
+// open starts the single-host view of a host with its own sources
+func (m ClusterModel) open(index int) (tea.Model, tea.Cmd) {
+	h := m.hosts[index]
+	host, err := InitialModel(h.Plugins...)
+	if err != nil {
+		m.err = fmt.Sprintf("%s: %v", h.Name, err)
+		return m, nil
+	}
+	host.SetKeyMap(m.keys)
+	if m.configure != nil {
+		m.configure(&host)
+	}
+	back := m.keys.Back.Help()
+	host.SetHost(fmt.Sprintf("%s (%s: %s)", h.Name, back.Key, back.Desc))
+
+	m.err = ""
+	m.generation++
+	m.host = &host
+	cmds := []tea.Cmd{}
+	for _, listen := range host.listenAll() {
+		cmds = append(cmds, m.wrap(listen))
+	}
+	updated, cmd := m.forward(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	return updated, tea.Batch(append(cmds, cmd)...)
+}
+
+// forward passes a message to the opened host
+func (m ClusterModel) forward(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.host == nil {
+		return m, nil
+	}
+	updated, cmd := m.host.Update(msg)
+	host := updated.(Model)
+	m.host = &host
+	return m, m.wrap(cmd)
+}
+
+// wrap tags the host's commands with the current generation
+func (m ClusterModel) wrap(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	generation := m.generation
+	return func() tea.Msg {
+		return hostMsg{generation: generation, msg: cmd()}
+	}
+}
+
+func (h *clusterHost) update(value any) {
+	switch msg := value.(type) {
+	case domain.CPUMemoryMetrics:
+		h.cpu = msg
+	case domain.GPUMetrics:
+		devices := msg.Devices
+		if len(devices) == 0 {
+			devices = []domain.GPUDeviceMetrics{{Usage: msg.GPUUsage, MemoryUsage: msg.GPUMemoryUsage}}
+		}
+		for len(h.gpus) < len(devices) {
+			h.gpus = append(h.gpus, &compactLine{chart: newPercentSparkline(clusterSparklineWidth)})
+		}
+		for i, device := range devices {
+			h.gpus[i].label = gpuLabel(device.Index, len(devices))
+			h.gpus[i].push(device.Usage)
+		}
+	}
+}
+
+// status describes whether the host is sending samples
+func (h *clusterHost) status() string {
+	switch {
+	case h.running == 0 && len(h.sources) > 0:
+		return "ended"
+	case h.lastSeen.IsZero():
+		return "waiting"
+	case time.Since(h.lastSeen) > hostTimeout:
+		return "offline"
+	}
+	return "up"
+}
+
+func (m ClusterModel) View() string {
+	if m.host != nil {
+		return m.host.View()
+	}
+
+	online := 0
+	for _, h := range m.hosts {
+		if h.status() == "up" {
+			online++
+		}
+	}
+	lines := []string{
+		fmt.Sprintf("Cluster: %d hosts, %d up", len(m.hosts), online),
+		labelStyle.Render(fmt.Sprintf("  %-*s %-*s %6s %6s  %s",
+			clusterHostWidth, "HOST", clusterStatusWidth, "STATUS", "CPU", "MEM", "GPUS / TOP PROCESS")),
+	}
+
+	// Keep the selected host on screen
+	visible := max(m.height-clusterHeaderHeight-statusBarHeight, 1)
+	first := max(m.selected-visible+1, 0)
+	for i := first; i < len(m.hosts) && i < first+visible; i++ {
+		lines = append(lines, m.hostRow(i))
+	}
+	for len(lines) < m.height-statusBarHeight {
+		lines = append(lines, "")
+	}
+
+	up, down := m.keys.Up, m.keys.Down
+	up.SetHelp(up.Help().Key, "previous host")
+	down.SetHelp(down.Help().Key, "next host")
+	status := m.help.ShortHelpView([]key.Binding{m.keys.Quit, up, down, m.keys.Open})
+	if m.err != "" {
+		status += " | " + m.err
+	}
+	return strings.Join(append(lines, status), "\n")
+}
+
+// hostRow renders one host; hosts that are not sending are dimmed
+func (m ClusterModel) hostRow(index int) string {
+	h := m.hosts[index]
+	status := h.status()
+
+	var row strings.Builder
+	fmt.Fprintf(&row, "%-*s %-*s ", clusterHostWidth, truncate(h.Name, clusterHostWidth), clusterStatusWidth, status)
+	if h.lastSeen.IsZero() {
+		fmt.Fprintf(&row, "%6s %6s ", "-", "-")
+	} else {
+		fmt.Fprintf(&row, "%5.1f%% %5.1f%% ", h.cpu.CPUUsageTotal, h.cpu.MemoryUsage)
+	}
+	for _, gpu := range h.gpus {
+		gpu.chart.Draw()
+		fmt.Fprintf(&row, " %s %s%3.0f%%", gpu.label, gpu.chart.View(), gpu.value)
+	}
+	if top := topProcesses(h.cpu.Processes, 1); len(top) > 0 {
+		fmt.Fprintf(&row, "  %s %.1f%%", top[0].Command, top[0].CPUPercent)
+	}
+
+	marker := "  "
+	if index == m.selected {
+		marker = "> "
+	}
+	style := lipgloss.NewStyle()
+	switch {
+	case index == m.selected:
+		style = style.Reverse(true)
+	case status != "up":
+		style = offlineStyle
+	}
+	return style.MaxWidth(m.width).Render(marker + row.String())
+}
@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/NimbleMarkets/ntcharts/sparkline"
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+const (
+	compactTopProcesses = 3
+	// label and value columns around each sparkline
+	compactLabelWidth = 5
+	compactValueWidth = 7
+)
+
+// compactLine is one labelled sparkline of the compact view
+type compactLine struct {
+	label string
+	value float64
+	chart *sparkline.Model
+}
+
+// CompactView is a dense single-screen summary for small terminals: a
+// one-line sparkline each for CPU, memory and every GPU, and the busiest
+// processes
+type CompactView struct {
+	focusState
+	cpu       *compactLine
+	memory    *compactLine
+	gpus      []*compactLine
+	processes []domain.CPUProcessInfo
+	width     int
+	height    int
+}
+
+func NewCompactView() *CompactView {
+	v := &CompactView{width: 40}
+	v.cpu = v.newLine("CPU")
+	v.memory = v.newLine("MEM")
+	return v
+}
+
+func (v *CompactView) newLine(label string) *compactLine {
+	return &compactLine{label: label, chart: newPercentSparkline(v.chartWidth())}
+}
+
+// newPercentSparkline returns a one-line sparkline scaled to 0-100
+func newPercentSparkline(width int) *sparkline.Model {
+	chart := sparkline.New(width, 1, sparkline.WithMaxValue(100))
+	return &chart
+}
+
+func (v *CompactView) Update(msg interface{}) {
+	switch msg := msg.(type) {
+	case domain.CPUMemoryMetrics:
+		v.cpu.push(msg.CPUUsageTotal)
+		v.memory.push(msg.MemoryUsage)
+		v.processes = topProcesses(msg.Processes, compactTopProcesses)
+	case domain.GPUMetrics:
+		devices := msg.Devices
+		if len(devices) == 0 {
+			devices = []domain.GPUDeviceMetrics{{Usage: msg.GPUUsage, MemoryUsage: msg.GPUMemoryUsage}}
+		}
+		for len(v.gpus) < len(devices) {
+			v.gpus = append(v.gpus, v.newLine(""))
+		}
+		for i, device := range devices {
+			v.gpus[i].label = gpuLabel(device.Index, len(devices))
+			v.gpus[i].push(device.Usage)
+		}
+	}
+}
+
+func (l *compactLine) push(value float64) {
+	l.value = value
+	l.chart.Push(value)
+}
+
+// gpuLabel numbers GPUs only when there is more than one
+func gpuLabel(index, count int) string {
+	if count == 1 {
+		return "GPU"
+	}
+	return fmt.Sprintf("GPU%d", index)
+}
+
+// topProcesses returns the n processes using the most CPU
+func topProcesses(processes []domain.CPUProcessInfo, n int) []domain.CPUProcessInfo {
+	top := append([]domain.CPUProcessInfo(nil), processes...)
+	sort.Slice(top, func(i, j int) bool {
+		return top[i].CPUPercent > top[j].CPUPercent
+	})
+	return top[:min(n, len(top))]
+}
+
+func (v *CompactView) View() string {
+	lines := make([]string, 0, 3+len(v.gpus)+len(v.processes))
+	for _, line := range append([]*compactLine{v.cpu, v.memory}, v.gpus...) {
+		line.chart.Draw()
+		lines = append(lines, fmt.Sprintf("%-*s%s%*.1f%%",
+			compactLabelWidth, line.label, line.chart.View(), compactValueWidth-1, line.value))
+	}
+	for _, p := range v.processes {
+		lines = append(lines, truncate(fmt.Sprintf("%7d %5.1f%% %s", p.Pid, p.CPUPercent, p.Command), v.width))
+	}
+
+	if v.height > 0 && len(lines) > v.height {
+		lines = lines[:v.height]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncate cuts s to at most width runes
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if width <= 0 || len(runes) <= width {
+		return s
+	}
+	return string(runes[:width])
+}
+
+func (v *CompactView) chartWidth() int {
+	return max(v.width-compactLabelWidth-compactValueWidth, 1)
+}
+
+func (v *CompactView) Resize(width, height int) {
+	v.width = width
+	v.height = height
+	for _, line := range append([]*compactLine{v.cpu, v.memory}, v.gpus...) {
+		line.chart.Resize(v.chartWidth(), 1)
+	}
+}
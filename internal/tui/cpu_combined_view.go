@@ -8,6 +8,7 @@ import (
 
 // CPUCombinedView combines the CPU heatmap and busiest cores view side by side
 type CPUCombinedView struct {
+    focusState
     heatmap      *CPUHeatmap
     busiestCores *BusiestCores
     width        int
@@ -24,15 +25,37 @@ func NewCPUCombinedView() *CPUCombinedView {
 }
 
 // Update updates both the heatmap and busiest cores with new metrics
-func (c *CPUCombinedView) Update(metrics domain.CPUMemoryMetrics) {
+func (c *CPUCombinedView) Update(msg interface{}) {
+    metrics, ok := msg.(domain.CPUMemoryMetrics)
+    if !ok {
+        return
+    }
     c.metrics = metrics
     c.heatmap.Update(metrics)
     c.busiestCores.Update(metrics)
 }
 
+// SetZoomed draws larger heatmap cells while the view fills the screen
+func (c *CPUCombinedView) SetZoomed(zoomed bool) {
+    c.heatmap.SetZoomed(zoomed)
+}
+
+// CycleHeatmapMetric switches the heatmap to the next per-core metric
+func (c *CPUCombinedView) CycleHeatmapMetric() {
+    c.heatmap.CycleMetric()
+}
+
+// ToggleFrequency switches the busiest cores graphs between usage and clock speed
+func (c *CPUCombinedView) ToggleFrequency() {
+    c.busiestCores.ToggleFrequency()
+}
+
 // View renders the heatmap and busiest cores side by side
 func (c *CPUCombinedView) View() string {
     heatmapView := strings.Split(c.heatmap.View(), "\n")
+    if len(c.metrics.CPUUsagePerCore) > 0 {
+        heatmapView = append(heatmapView, c.heatmap.MetricLabel())
+    }
     busiestView := strings.Split(c.busiestCores.View(), "\n")
     
     // Determine the maximum number of lines
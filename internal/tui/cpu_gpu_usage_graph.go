@@ -1,6 +1,10 @@
 package tui
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/NimbleMarkets/ntcharts/canvas/runes"
 	"github.com/NimbleMarkets/ntcharts/linechart/streamlinechart"
 	"github.com/charmbracelet/lipgloss"
@@ -24,8 +28,32 @@ var axisStyle = lipgloss.NewStyle().
 var labelStyle = lipgloss.NewStyle().
 	Foreground(lipgloss.Color("6")) // cyan
 
+// cpuTimeLayer is one band of the stacked CPU time view. Each layer's data
+// set holds the cumulative sum of its own bucket and all layers below it.
+type cpuTimeLayer struct {
+	dataSet string
+	label   string
+	style   lipgloss.Style
+	value   func(domain.CPUTimeBreakdown) float64
+}
+
+var cpuTimeLayers = []cpuTimeLayer{
+	{"t1user", "user", lipgloss.NewStyle().Foreground(lipgloss.Color("4")), func(b domain.CPUTimeBreakdown) float64 { return b.User }},
+	{"t2nice", "nice", lipgloss.NewStyle().Foreground(lipgloss.Color("12")), func(b domain.CPUTimeBreakdown) float64 { return b.Nice }},
+	{"t3system", "sys", lipgloss.NewStyle().Foreground(lipgloss.Color("1")), func(b domain.CPUTimeBreakdown) float64 { return b.System }},
+	{"t4iowait", "iowait", lipgloss.NewStyle().Foreground(lipgloss.Color("5")), func(b domain.CPUTimeBreakdown) float64 { return b.Iowait }},
+	{"t5irq", "irq", lipgloss.NewStyle().Foreground(lipgloss.Color("3")), func(b domain.CPUTimeBreakdown) float64 { return b.Irq + b.Softirq }},
+	{"t6steal", "steal", lipgloss.NewStyle().Foreground(lipgloss.Color("9")), func(b domain.CPUTimeBreakdown) float64 { return b.Steal }},
+	{"t7guest", "guest", lipgloss.NewStyle().Foreground(lipgloss.Color("13")), func(b domain.CPUTimeBreakdown) float64 { return b.Guest }},
+}
+
 type CPUGPUUsageGraph struct {
-	slc streamlinechart.Model
+	focusState
+	slc           streamlinechart.Model
+	showBreakdown bool
+	zoomed        bool
+	step          time.Duration // spacing of the samples, for the time axis
+	lastTimes     domain.CPUTimeBreakdown
 }
 
 func NewCPUGPUUsageGraph() *CPUGPUUsageGraph {
@@ -37,6 +65,9 @@ func NewCPUGPUUsageGraph() *CPUGPUUsageGraph {
 		streamlinechart.WithDataSetStyles(gpuDataSet, runes.ThinLineStyle, graphLineStyleGPU),
 		streamlinechart.WithDataSetStyles(cpuDataSet, runes.ThinLineStyle, graphLineStyleCPU),
 	)
+	for _, layer := range cpuTimeLayers {
+		slc.SetDataSetStyles(layer.dataSet, runes.ThinLineStyle, layer.style)
+	}
 	slc.XLabelFormatter = func(int, float64) string {
 		return " "
 	}
@@ -45,7 +76,8 @@ func NewCPUGPUUsageGraph() *CPUGPUUsageGraph {
 	slc.DrawXYAxisAndLabel()
 
 	return &CPUGPUUsageGraph{
-		slc: slc,
+		slc:  slc,
+		step: time.Second,
 	}
 }
 
@@ -60,18 +92,67 @@ func (g *CPUGPUUsageGraph) Update(msg any) {
 
 func (g *CPUGPUUsageGraph) updateCPU(cpuMetrics domain.CPUMemoryMetrics) {
 	g.slc.PushDataSet(cpuDataSet, cpuMetrics.CPUUsageTotal)
+
+	g.lastTimes = cpuMetrics.CPUTimesTotal
+	var cumulative float64
+	for _, layer := range cpuTimeLayers {
+		cumulative += layer.value(cpuMetrics.CPUTimesTotal)
+		g.slc.PushDataSet(layer.dataSet, cumulative)
+	}
 }
 
 func (g *CPUGPUUsageGraph) updateGPU(gpuMetrics domain.GPUMetrics) {
 	g.slc.PushDataSet(gpuDataSet, gpuMetrics.GPUUsage)
 }
 
+// ToggleBreakdown switches between total CPU usage and the stacked
+// user/system/iowait/irq/steal view
+func (g *CPUGPUUsageGraph) ToggleBreakdown() {
+	g.showBreakdown = !g.showBreakdown
+}
+
+// SetZoomed adds a time axis below the graph while it fills the screen
+func (g *CPUGPUUsageGraph) SetZoomed(zoomed bool) {
+	g.zoomed = zoomed
+}
+
 func (g *CPUGPUUsageGraph) View() string {
-	g.slc.DrawAll()
-	return g.slc.View()
+	if !g.showBreakdown {
+		g.slc.DrawDataSets([]string{cpuDataSet, gpuDataSet})
+		return g.chartView()
+	}
+
+	// Draw the outermost band first so lower bands stay visible where they meet
+	names := make([]string, 0, len(cpuTimeLayers)+1)
+	for i := len(cpuTimeLayers) - 1; i >= 0; i-- {
+		names = append(names, cpuTimeLayers[i].dataSet)
+	}
+	names = append(names, gpuDataSet)
+	g.slc.DrawDataSets(names)
+	return lipgloss.JoinVertical(lipgloss.Left, g.chartView(), g.legendView())
+}
+
+func (g *CPUGPUUsageGraph) chartView() string {
+	if !g.zoomed {
+		return g.slc.View()
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, g.slc.View(), timeAxisView(g.slc.Width(), g.slc.Origin().X, g.step))
+}
+
+func (g *CPUGPUUsageGraph) legendView() string {
+	parts := make([]string, 0, len(cpuTimeLayers))
+	for _, layer := range cpuTimeLayers {
+		parts = append(parts, layer.style.Render(fmt.Sprintf("■ %s %.1f%%", layer.label, layer.value(g.lastTimes))))
+	}
+	return "    " + strings.Join(parts, "  ")
 }
 
 func (g *CPUGPUUsageGraph) Resize(width, height int) {
 	g.slc.Resize(width, height)
 }
 
+
+// SampleAge reports how many samples back the column under x, y shows
+func (g *CPUGPUUsageGraph) SampleAge(x, y int) (int, bool) {
+	return streamAge(&g.slc, x, y)
+}
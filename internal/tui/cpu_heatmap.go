@@ -4,17 +4,77 @@ import (
     "math"
 
     "github.com/NimbleMarkets/ntcharts/heatmap"
+    "github.com/charmbracelet/lipgloss"
     "github.com/jonsampson/mim/internal/domain"
 )
 
+// heatmapMetric selects which per-core value the heatmap shows
+type heatmapMetric struct {
+    label string
+    value func(metrics domain.CPUMemoryMetrics, core int) float64
+}
+
+var heatmapMetrics = []heatmapMetric{
+    {"busy", func(m domain.CPUMemoryMetrics, core int) float64 { return m.CPUUsagePerCore[core] }},
+    {"user", func(m domain.CPUMemoryMetrics, core int) float64 { return coreTimes(m, core).User + coreTimes(m, core).Nice }},
+    {"sys", func(m domain.CPUMemoryMetrics, core int) float64 { return coreTimes(m, core).System }},
+    {"iowait", func(m domain.CPUMemoryMetrics, core int) float64 { return coreTimes(m, core).Iowait }},
+    {"irq", func(m domain.CPUMemoryMetrics, core int) float64 { return coreTimes(m, core).Irq + coreTimes(m, core).Softirq }},
+    {"steal", func(m domain.CPUMemoryMetrics, core int) float64 { return coreTimes(m, core).Steal }},
+}
+
+func coreTimes(m domain.CPUMemoryMetrics, core int) domain.CPUTimeBreakdown {
+    if core < len(m.CPUTimesPerCore) {
+        return m.CPUTimesPerCore[core]
+    }
+    return domain.CPUTimeBreakdown{}
+}
+
+// heatmapGroup is one socket/NUMA node block of the heatmap
+type heatmapGroup struct {
+    hm    *heatmap.Model
+    label string
+}
+
 type CPUHeatmap struct {
-    hm              *heatmap.Model
-    squareDimension int
-    metrics         domain.CPUMemoryMetrics
+    groups      []heatmapGroup
+    metrics     domain.CPUMemoryMetrics
+    metricIndex int
+    zoomed      bool
+    grouper     *domain.TopologyGrouper
 }
 
+// Heatmap cell size in characters while zoomed; roughly square on screen
+const (
+    zoomCellWidth  = 4
+    zoomCellHeight = 2
+)
+
 func NewCPUHeatmap() *CPUHeatmap {
-    return &CPUHeatmap{}
+    return &CPUHeatmap{
+        grouper: domain.NewTopologyGrouper(),
+    }
+}
+
+// CycleMetric switches the heatmap to the next per-core metric
+func (c *CPUHeatmap) CycleMetric() {
+    c.metricIndex = (c.metricIndex + 1) % len(heatmapMetrics)
+    if len(c.metrics.CPUUsagePerCore) > 0 {
+        c.updateHeatmap()
+    }
+}
+
+// SetZoomed switches between one character per core and larger cells
+func (c *CPUHeatmap) SetZoomed(zoomed bool) {
+    c.zoomed = zoomed
+    if len(c.metrics.CPUUsagePerCore) > 0 {
+        c.updateHeatmap()
+    }
+}
+
+// MetricLabel returns the name of the metric currently shown
+func (c *CPUHeatmap) MetricLabel() string {
+    return heatmapMetrics[c.metricIndex].label
 }
 
 func (c *CPUHeatmap) Update(msg interface{}) {
@@ -26,38 +86,75 @@ func (c *CPUHeatmap) Update(msg interface{}) {
 }
 
 func (c *CPUHeatmap) updateHeatmap() {
-    c.squareDimension = int(math.Ceil(math.Sqrt(float64(len(c.metrics.CPUUsagePerCore)))))
-    heatMap := heatmap.New(c.squareDimension+1,
-        c.squareDimension+1,
+    metric := heatmapMetrics[c.metricIndex]
+    topologyGroups := c.grouper.Group(c.metrics.Topology, len(c.metrics.CPUUsagePerCore))
+
+    c.groups = c.groups[:0]
+    for _, group := range topologyGroups {
+        values := make([]float64, len(group.CPUs))
+        for i, cpu := range group.CPUs {
+            values[i] = metric.value(c.metrics, cpu)
+        }
+
+        label := ""
+        if len(topologyGroups) > 1 {
+            label = group.Label()
+        }
+        cellWidth, cellHeight := 1, 1
+        if c.zoomed {
+            cellWidth, cellHeight = zoomCellWidth, zoomCellHeight
+        }
+        c.groups = append(c.groups, heatmapGroup{
+            hm:    buildHeatmap(values, group.ThreadsPerCore, cellWidth, cellHeight),
+            label: label,
+        })
+    }
+}
+
+// buildHeatmap lays values out row by row from the top left, each value
+// filling cellWidth x cellHeight characters. The row width is a multiple of
+// threadsPerCore so SMT siblings share a row.
+func buildHeatmap(values []float64, threadsPerCore, cellWidth, cellHeight int) *heatmap.Model {
+    threadsPerCore = max(threadsPerCore, 1)
+    cols := int(math.Ceil(math.Sqrt(float64(len(values)))))
+    cols = max((cols+threadsPerCore-1)/threadsPerCore*threadsPerCore, 1)
+    rows := max((len(values)+cols-1)/cols, 1)
+    width, height := cols*cellWidth, rows*cellHeight
+
+    heatMap := heatmap.New(width+1,
+        height+1,
         heatmap.WithValueRange(0, 100),
     )
-    
-    matrix := make([][]float64, c.squareDimension)
+
+    matrix := make([][]float64, width)
     for i := range matrix {
-        matrix[i] = make([]float64, c.squareDimension)
-    }
-
-    core := 0
-    for i := 0; i < c.squareDimension; i++ {
-        for j := 0; j < c.squareDimension; j++ {
-            if core < len(c.metrics.CPUUsagePerCore) {
-                // Rotate 90 degrees clockwise
-                matrix[j][c.squareDimension-1-i] = c.metrics.CPUUsagePerCore[core]
-                core++
-            } else {
-                matrix[j][c.squareDimension-1-i] = 0
+        matrix[i] = make([]float64, height)
+    }
+
+    for i, value := range values {
+        // Matrix is indexed [x][y] with y growing upwards
+        x, y := i%cols*cellWidth, (rows-1-i/cols)*cellHeight
+        for dx := 0; dx < cellWidth; dx++ {
+            for dy := 0; dy < cellHeight; dy++ {
+                matrix[x+dx][y+dy] = value
             }
         }
     }
 
     heatMap.PushAllMatrixRow(matrix)
-    c.hm = &heatMap
+    return &heatMap
 }
 
 func (c *CPUHeatmap) View() string {
-    if c.hm == nil {
+    if len(c.groups) == 0 {
         return ""
     }
-    c.hm.Draw()
-    return c.hm.View()
-}
\ No newline at end of file
+
+    views := make([]string, 0, len(c.groups))
+    for _, group := range c.groups {
+        group.hm.Draw()
+        views = append(views, lipgloss.NewStyle().PaddingRight(1).Render(
+            lipgloss.JoinVertical(lipgloss.Left, group.hm.View(), group.label)))
+    }
+    return lipgloss.JoinHorizontal(lipgloss.Top, views...)
+}
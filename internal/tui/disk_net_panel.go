@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NimbleMarkets/ntcharts/sparkline"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+var diskNetHeaderStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("4")) // blue
+
+// diskNetRow is one device or interface with a history of its total
+// throughput
+type diskNetRow struct {
+	label string
+	in    float64
+	out   float64
+	chart *sparkline.Model
+}
+
+// DiskNetPanel lists block device and network interface throughput with a
+// sparkline per device
+type DiskNetPanel struct {
+	focusState
+	disks      []*diskNetRow
+	interfaces []*diskNetRow
+	width      int
+	height     int
+}
+
+func NewDiskNetPanel() *DiskNetPanel {
+	return &DiskNetPanel{width: 80}
+}
+
+func (p *DiskNetPanel) Update(msg interface{}) {
+	if p == nil {
+		return
+	}
+	switch msg := msg.(type) {
+	case domain.DiskNetMetrics:
+		for _, disk := range msg.Disks {
+			p.disks = p.pushRow(p.disks, disk.Name, disk.ReadBytesPerSec, disk.WriteBytesPerSec)
+		}
+		for _, iface := range msg.Interfaces {
+			p.interfaces = p.pushRow(p.interfaces, iface.Name, iface.RecvBytesPerSec, iface.SentBytesPerSec)
+		}
+	}
+}
+
+// pushRow records a sample, adding a row the first time a device is seen
+func (p *DiskNetPanel) pushRow(rows []*diskNetRow, label string, in, out float64) []*diskNetRow {
+	var row *diskNetRow
+	for _, r := range rows {
+		if r.label == label {
+			row = r
+			break
+		}
+	}
+	if row == nil {
+		chart := sparkline.New(p.chartWidth(), 1)
+		row = &diskNetRow{label: label, chart: &chart}
+		rows = append(rows, row)
+	}
+	row.in, row.out = in, out
+	row.chart.Push(in + out)
+	return rows
+}
+
+func (p *DiskNetPanel) View() string {
+	if p == nil {
+		return ""
+	}
+
+	lines := []string{diskNetHeaderStyle.Render("    Disks          read/s     write/s")}
+	lines = append(lines, p.renderRows(p.disks)...)
+	lines = append(lines, "", diskNetHeaderStyle.Render("    Network          rx/s        tx/s"))
+	lines = append(lines, p.renderRows(p.interfaces)...)
+
+	if p.height > 0 && len(lines) > p.height {
+		lines = lines[:p.height]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *DiskNetPanel) renderRows(rows []*diskNetRow) []string {
+	if len(rows) == 0 {
+		return []string{psiUnavailableStyle.Render("    waiting for samples")}
+	}
+
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		row.chart.DrawBraille()
+		lines = append(lines, fmt.Sprintf("    %-10.10s %10s %11s  %s",
+			row.label,
+			formatBytes(uint64(row.in)),
+			formatBytes(uint64(row.out)),
+			row.chart.View(),
+		))
+	}
+	return lines
+}
+
+func (p *DiskNetPanel) chartWidth() int {
+	// label and rates take roughly 40 columns
+	return max(p.width-42, 5)
+}
+
+func (p *DiskNetPanel) Resize(width, height int) {
+	if p == nil {
+		return
+	}
+	p.width = width
+	p.height = height
+	for _, row := range append(p.disks, p.interfaces...) {
+		row.chart.Resize(p.chartWidth(), 1)
+	}
+}
@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"os"
+	"slices"
+	"time"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/jonsampson/mim/internal/export"
+	"github.com/jonsampson/mim/internal/store"
+)
+
+// DefaultHistoryRetention is how far back a paused display can be scrubbed
+const DefaultHistoryRetention = 5 * time.Minute
+
+// historyEntry is one collector message as received by the model
+type historyEntry struct {
+	at     time.Time
+	source string
+	value  any
+}
+
+// metricsHistory keeps every collector message for a bounded time so the
+// display can be rebuilt as it was at an earlier instant
+type metricsHistory struct {
+	entries   []historyEntry
+	retention time.Duration
+}
+
+func newMetricsHistory(retention time.Duration) *metricsHistory {
+	return &metricsHistory{retention: retention}
+}
+
+// newArchiveHistory holds samples loaded from the store, step apart, as the
+// collector messages they were made from
+func newArchiveHistory(samples []store.Sample, step time.Duration) *metricsHistory {
+	span := samples[len(samples)-1].Time.Sub(samples[0].Time)
+	h := newMetricsHistory(span + step)
+	for _, sample := range samples {
+		h.add(sample.Time, "store", sample.CPUMemory())
+		if gpu, ok := sample.GPUMetrics(); ok {
+			h.add(sample.Time, "store", gpu)
+		}
+	}
+	return h
+}
+
+// add records a message and drops entries older than the retention. Process
+// lists are copied, so a source reusing its buffers cannot rewrite the past.
+func (h *metricsHistory) add(at time.Time, source string, value any) {
+	switch v := value.(type) {
+	case domain.CPUMemoryMetrics:
+		v.Processes = slices.Clone(v.Processes)
+		value = v
+	case domain.GPUMetrics:
+		v.Processes = slices.Clone(v.Processes)
+		value = v
+	}
+	h.entries = append(h.entries, historyEntry{at: at, source: source, value: value})
+
+	cutoff := at.Add(-h.retention)
+	drop := 0
+	for drop < len(h.entries) && h.entries[drop].at.Before(cutoff) {
+		drop++
+	}
+	if drop > 0 {
+		// Clear dropped entries so their process lists can be collected
+		clear(h.entries[:drop])
+		h.entries = h.entries[drop:]
+	}
+}
+
+// between returns the entries received after from and up to and including to
+func (h *metricsHistory) between(from, to time.Time) []historyEntry {
+	start := len(h.entries)
+	for i, entry := range h.entries {
+		if entry.at.After(from) {
+			start = i
+			break
+		}
+	}
+	end := start
+	for end < len(h.entries) && !h.entries[end].at.After(to) {
+		end++
+	}
+	return h.entries[start:end]
+}
+
+// oldest and newest return the time span covered; zero when empty
+func (h *metricsHistory) oldest() time.Time {
+	if len(h.entries) == 0 {
+		return time.Time{}
+	}
+	return h.entries[0].at
+}
+
+func (h *metricsHistory) newest() time.Time {
+	if len(h.entries) == 0 {
+		return time.Time{}
+	}
+	return h.entries[len(h.entries)-1].at
+}
+
+// latest returns the entry n messages back among those at or before to that
+// match; n 0 is the most recent
+func (h *metricsHistory) latest(to time.Time, n int, match func(value any) bool) (historyEntry, bool) {
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		entry := h.entries[i]
+		if entry.at.After(to) || !match(entry.value) {
+			continue
+		}
+		if n == 0 {
+			return entry, true
+		}
+		n--
+	}
+	return historyEntry{}, false
+}
+
+// writeCSV writes the retained CPU, memory and GPU samples and their
+// processes as CSV, one row per CPU sample, and returns the row count. When
+// the history holds GPU samples, rows start at the first one so the GPU
+// columns are part of the schema.
+func (h *metricsHistory) writeCSV(systemPath, processPath string) (int, error) {
+	system, err := os.Create(systemPath)
+	if err != nil {
+		return 0, err
+	}
+	defer system.Close()
+	processes, err := os.Create(processPath)
+	if err != nil {
+		return 0, err
+	}
+	defer processes.Close()
+
+	isGPU := func(value any) bool {
+		_, ok := value.(domain.GPUMetrics)
+		return ok
+	}
+	_, gpuPending := h.latest(h.newest(), 0, isGPU)
+
+	writer := export.NewCSVWriter(system, processes)
+	rows := 0
+	for _, entry := range h.entries {
+		writer.Update(entry.value)
+		if isGPU(entry.value) {
+			gpuPending = false
+		}
+		if _, ok := entry.value.(domain.CPUMemoryMetrics); !ok || gpuPending {
+			continue
+		}
+		if err := writer.WriteRow(entry.at); err != nil {
+			return rows, err
+		}
+		rows++
+	}
+	return rows, nil
+}
@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyMap holds every key binding of the TUI. Bindings can be remapped by
+// action name, see Remap.
+type KeyMap struct {
+	Quit          key.Binding
+	Help          key.Binding
+	Up            key.Binding
+	Down          key.Binding
+	PageUp        key.Binding
+	PageDown      key.Binding
+	Top           key.Binding
+	Bottom        key.Binding
+	Screen        key.Binding // the nth key selects the nth screen
+	NextScreen    key.Binding
+	PrevScreen    key.Binding
+	NextFocus     key.Binding
+	PrevFocus     key.Binding
+	Zoom          key.Binding
+	Unzoom        key.Binding
+	Pause         key.Binding
+	StepBack      key.Binding
+	StepForward   key.Binding
+	JumpBack      key.Binding
+	JumpForward   key.Binding
+	Breakdown     key.Binding
+	HeatmapMetric key.Binding
+	Frequency     key.Binding
+	ExportHistory key.Binding
+	Stats         key.Binding
+	Open          key.Binding // cluster overview: show the selected host
+	Back          key.Binding // host view: return to the cluster overview
+}
+
+// DefaultKeyMap returns the built-in bindings
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Quit:          key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+		Help:          key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+		Up:            key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "scroll up")),
+		Down:          key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "scroll down")),
+		PageUp:        key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "half page up")),
+		PageDown:      key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdown", "half page down")),
+		Top:           key.NewBinding(key.WithKeys("home"), key.WithHelp("home", "go to top")),
+		Bottom:        key.NewBinding(key.WithKeys("end"), key.WithHelp("end", "go to bottom")),
+		Screen:        key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"), key.WithHelp("1-9", "select screen")),
+		NextScreen:    key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next screen")),
+		PrevScreen:    key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "previous screen")),
+		NextFocus:     key.NewBinding(key.WithKeys("]"), key.WithHelp("]", "focus next panel")),
+		PrevFocus:     key.NewBinding(key.WithKeys("["), key.WithHelp("[", "focus previous panel")),
+		Zoom:          key.NewBinding(key.WithKeys("z"), key.WithHelp("z", "zoom")),
+		Unzoom:        key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "unzoom")),
+		Pause:         key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "pause")),
+		StepBack:      key.NewBinding(key.WithKeys("left"), key.WithHelp("←", "step back 1s")),
+		StepForward:   key.NewBinding(key.WithKeys("right"), key.WithHelp("→", "step forward 1s")),
+		JumpBack:      key.NewBinding(key.WithKeys("shift+left"), key.WithHelp("shift+←", "step back 10s")),
+		JumpForward:   key.NewBinding(key.WithKeys("shift+right"), key.WithHelp("shift+→", "step forward 10s")),
+		Breakdown:     key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "CPU breakdown")),
+		HeatmapMetric: key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "heatmap metric")),
+		Frequency:     key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "frequency")),
+		ExportHistory: key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "export history to CSV")),
+		Stats:         key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "statistics")),
+		Open:          key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open host")),
+		Back:          key.NewBinding(key.WithKeys("backspace"), key.WithHelp("backspace", "back to cluster")),
+	}
+}
+
+// keyAction names a binding for remapping
+type keyAction struct {
+	name    string
+	binding *key.Binding
+}
+
+func (k *KeyMap) actions() []keyAction {
+	return []keyAction{
+		{"quit", &k.Quit},
+		{"help", &k.Help},
+		{"up", &k.Up},
+		{"down", &k.Down},
+		{"page-up", &k.PageUp},
+		{"page-down", &k.PageDown},
+		{"top", &k.Top},
+		{"bottom", &k.Bottom},
+		{"screen", &k.Screen},
+		{"next-screen", &k.NextScreen},
+		{"prev-screen", &k.PrevScreen},
+		{"next-focus", &k.NextFocus},
+		{"prev-focus", &k.PrevFocus},
+		{"zoom", &k.Zoom},
+		{"unzoom", &k.Unzoom},
+		{"pause", &k.Pause},
+		{"step-back", &k.StepBack},
+		{"step-forward", &k.StepForward},
+		{"jump-back", &k.JumpBack},
+		{"jump-forward", &k.JumpForward},
+		{"breakdown", &k.Breakdown},
+		{"heatmap-metric", &k.HeatmapMetric},
+		{"frequency", &k.Frequency},
+		{"export-history", &k.ExportHistory},
+		{"stats", &k.Stats},
+		{"open", &k.Open},
+		{"back", &k.Back},
+	}
+}
+
+// Remap replaces the keys of the named actions, for example
+// {"quit": ["q", "x"]}. An empty list unbinds the action.
+func (k *KeyMap) Remap(bindings map[string][]string) error {
+	byName := make(map[string]*key.Binding)
+	for _, action := range k.actions() {
+		byName[action.name] = action.binding
+	}
+
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		binding, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("unknown key action %q", name)
+		}
+		keys := bindings[name]
+		if len(keys) == 0 {
+			binding.Unbind()
+			continue
+		}
+		binding.SetKeys(keys...)
+		binding.SetHelp(keyLabel(keys), binding.Help().Desc)
+	}
+	return nil
+}
+
+// Conflicts describes every key bound to more than one action
+func (k *KeyMap) Conflicts() []string {
+	owners := make(map[string][]string)
+	var order []string
+	for _, action := range k.actions() {
+		for _, pressed := range action.binding.Keys() {
+			if len(owners[pressed]) == 0 {
+				order = append(order, pressed)
+			}
+			owners[pressed] = append(owners[pressed], action.name)
+		}
+	}
+
+	var conflicts []string
+	for _, pressed := range order {
+		if len(owners[pressed]) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("key %q is bound to %s", keyLabel([]string{pressed}), strings.Join(owners[pressed], ", ")))
+		}
+	}
+	return conflicts
+}
+
+// ShortHelp lists the bindings shown in the status bar
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Quit, k.Help, k.Pause, k.Screen, k.NextFocus, k.Zoom}
+}
+
+// FullHelp groups all bindings for the help overlay
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Quit, k.Help, k.Up, k.Down, k.PageUp, k.PageDown, k.Top, k.Bottom},
+		{k.Screen, k.NextScreen, k.PrevScreen, k.NextFocus, k.PrevFocus, k.Zoom, k.Unzoom},
+		{k.Pause, k.StepBack, k.StepForward, k.JumpBack, k.JumpForward, k.ExportHistory},
+		{k.Breakdown, k.HeatmapMetric, k.Frequency, k.Stats},
+	}
+}
+
+// keyLabel renders keys for help text
+func keyLabel(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		if k == " " {
+			k = "space"
+		}
+		labels[i] = k
+	}
+	return strings.Join(labels, "/")
+}
@@ -0,0 +1,264 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Built-in panel names usable in layouts. Plugin panels use their plugin name.
+const (
+	PanelCPUGraph  = "cpu-graph"
+	PanelCPUCores  = "cpu-cores"
+	PanelMemory    = "memory"
+	PanelProcesses = "processes"
+	PanelCompact   = "compact"
+	PanelWatched   = "watched" // only with a watch list, see Model.SetWatchList
+
+	// Single process tables, used by the tabs
+	PanelCPUProcesses    = "cpu-processes"
+	PanelMemoryProcesses = "memory-processes"
+	PanelIOProcesses     = "io-processes"
+	PanelGPUProcesses    = "gpu-processes"
+)
+
+// builtinPanels lists the panel names the model provides itself
+var builtinPanels = []string{
+	PanelCPUGraph, PanelCPUCores, PanelMemory, PanelProcesses, PanelCompact, PanelWatched,
+	PanelCPUProcesses, PanelMemoryProcesses, PanelIOProcesses, PanelGPUProcesses,
+}
+
+// PanelNames returns the names a layout may refer to: the built-in panels and
+// the plugins that have a panel, whether or not they run on this machine
+func PanelNames(plugins []Plugin) []string {
+	names := append([]string(nil), builtinPanels...)
+	for _, plugin := range plugins {
+		if plugin.NewPanel != nil {
+			names = append(names, plugin.Name)
+		}
+	}
+	return names
+}
+
+// CheckPanelNames returns an error naming every entry of used missing from
+// known
+func CheckPanelNames(used, known []string) error {
+	isKnown := make(map[string]bool, len(known))
+	for _, name := range known {
+		isKnown[name] = true
+	}
+	var unknown []string
+	for _, name := range used {
+		if !isKnown[name] && !slices.Contains(unknown, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown panels %v (available: %v)", unknown, slices.Sorted(slices.Values(known)))
+	}
+	return nil
+}
+
+// Panels returns the name of every panel the layout places, in order
+func (l Layout) Panels() []string {
+	var names []string
+	for _, row := range l.Rows {
+		for _, panel := range row.Panels {
+			names = append(names, panel.Name)
+		}
+	}
+	return names
+}
+
+// Layout arranges named panels in rows, each row split into columns
+type Layout struct {
+	Rows []LayoutRow
+}
+
+// LayoutRow is a horizontal band of panels. Lines fixes the height; otherwise
+// Weight shares the height left after fixed rows. A row with neither uses
+// the natural height of its panels.
+type LayoutRow struct {
+	Lines  int
+	Weight float64
+	Panels []LayoutPanel
+}
+
+// LayoutPanel places a panel in a row. Weight is its share of the row width
+// (default 1).
+type LayoutPanel struct {
+	Name   string
+	Weight float64
+	Hidden bool
+}
+
+// cell is a panel with its computed size; height 0 means natural height
+type cell struct {
+	name          string
+	width, height int
+}
+
+// DefaultLayout reproduces the classic single-column view
+func DefaultLayout() Layout {
+	return Layout{Rows: []LayoutRow{
+		{Panels: []LayoutPanel{{Name: "activity"}}},
+		{Panels: []LayoutPanel{{Name: PanelCPUGraph}}},
+		{Panels: []LayoutPanel{{Name: PanelCPUCores}}},
+		{Panels: []LayoutPanel{{Name: "psi"}}},
+		{Panels: []LayoutPanel{{Name: PanelMemory}}},
+		{Panels: []LayoutPanel{{Name: PanelProcesses}}},
+	}}
+}
+
+// LayoutPresets returns the built-in layouts by name
+func LayoutPresets() map[string]Layout {
+	return map[string]Layout{
+		"default": DefaultLayout(),
+		// GPU box: utilization and memory side by side, processes below
+		"gpu-box": {Rows: []LayoutRow{
+			{Lines: 1, Panels: []LayoutPanel{{Name: "activity"}}},
+			{Weight: 1, Panels: []LayoutPanel{{Name: PanelCPUGraph}, {Name: PanelMemory}}},
+			{Panels: []LayoutPanel{{Name: PanelProcesses}}},
+		}},
+		// CPU server: large core view and pressure, memory beside the CPU graph
+		"cpu-server": {Rows: []LayoutRow{
+			{Lines: 1, Panels: []LayoutPanel{{Name: "activity"}}},
+			{Weight: 1, Panels: []LayoutPanel{{Name: PanelCPUGraph, Weight: 2}, {Name: PanelMemory}}},
+			{Weight: 2, Panels: []LayoutPanel{{Name: PanelCPUCores}}},
+			{Panels: []LayoutPanel{{Name: "psi"}}},
+			{Panels: []LayoutPanel{{Name: PanelProcesses}}},
+		}},
+		// Laptop: compact graphs and the process tables only
+		"laptop": {Rows: []LayoutRow{
+			{Weight: 1, Panels: []LayoutPanel{{Name: PanelCPUGraph}, {Name: PanelMemory}}},
+			{Panels: []LayoutPanel{{Name: PanelProcesses}}},
+		}},
+	}
+}
+
+// LayoutPreset looks up a built-in layout by name
+func LayoutPreset(name string) (Layout, error) {
+	layout, ok := LayoutPresets()[name]
+	if !ok {
+		names := make([]string, 0, len(LayoutPresets()))
+		for n := range LayoutPresets() {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Layout{}, fmt.Errorf("unknown layout preset %q (available: %v)", name, names)
+	}
+	return layout, nil
+}
+
+// Hide returns a copy of the layout with the named panels hidden
+func (l Layout) Hide(names ...string) Layout {
+	hidden := make(map[string]bool, len(names))
+	for _, name := range names {
+		hidden[name] = true
+	}
+
+	rows := make([]LayoutRow, len(l.Rows))
+	for i, row := range l.Rows {
+		rows[i] = row
+		rows[i].Panels = make([]LayoutPanel, len(row.Panels))
+		for j, panel := range row.Panels {
+			panel.Hidden = panel.Hidden || hidden[panel.Name]
+			rows[i].Panels[j] = panel
+		}
+	}
+	return Layout{Rows: rows}
+}
+
+// compute sizes every visible panel for the given area. Panels for which
+// exists returns false (for example a disabled collector) are dropped, and
+// rows left empty take no space. When there are weighted rows, measure
+// reports the natural height of a panel so natural rows can be left room.
+func (l Layout) compute(width, height int, exists func(name string) bool, measure func(c cell) int) [][]cell {
+	var rows []LayoutRow
+	fixed, totalWeight := 0, 0.0
+	for _, row := range l.Rows {
+		var panels []LayoutPanel
+		for _, panel := range row.Panels {
+			if !panel.Hidden && exists(panel.Name) {
+				panels = append(panels, panel)
+			}
+		}
+		if len(panels) == 0 {
+			continue
+		}
+		row.Panels = panels
+		rows = append(rows, row)
+		if row.Lines > 0 {
+			fixed += row.Lines
+		} else {
+			totalWeight += row.Weight
+		}
+	}
+
+	cells := make([][]cell, len(rows))
+	for i, row := range rows {
+		cells[i] = splitRow(row.Panels, width, row.Lines)
+		if row.Lines == 0 && row.Weight == 0 && totalWeight > 0 && measure != nil {
+			natural := 0
+			for _, c := range cells[i] {
+				natural = max(natural, measure(c))
+			}
+			fixed += natural
+		}
+	}
+
+	remaining := max(height-fixed, 0)
+	for i, row := range rows {
+		if row.Lines == 0 && row.Weight > 0 {
+			rowHeight := max(int(float64(remaining)*row.Weight/totalWeight), 1)
+			for j := range cells[i] {
+				cells[i][j].height = rowHeight
+			}
+		}
+	}
+	return cells
+}
+
+// splitRow divides width between panels by weight, giving any rounding
+// remainder to the last panel
+func splitRow(panels []LayoutPanel, width, height int) []cell {
+	total := 0.0
+	for _, panel := range panels {
+		total += panelWeight(panel)
+	}
+
+	cells := make([]cell, len(panels))
+	used := 0
+	for i, panel := range panels {
+		w := int(float64(width) * panelWeight(panel) / total)
+		if i == len(panels)-1 {
+			w = width - used
+		}
+		used += w
+		cells[i] = cell{name: panel.Name, width: max(w, 0), height: height}
+	}
+	return cells
+}
+
+func panelWeight(panel LayoutPanel) float64 {
+	if panel.Weight <= 0 {
+		return 1
+	}
+	return panel.Weight
+}
+
+// fitCell pads or truncates a rendered panel to its cell. Natural-height
+// cells keep their own height and a zero width leaves the view untouched.
+func fitCell(view string, c cell) string {
+	if c.width > 0 {
+		view = lipgloss.PlaceHorizontal(c.width, lipgloss.Left,
+			lipgloss.NewStyle().MaxWidth(c.width).Render(view))
+	}
+	if c.height > 0 {
+		view = lipgloss.PlaceVertical(c.height, lipgloss.Top,
+			lipgloss.NewStyle().MaxHeight(c.height).Render(view))
+	}
+	return view
+}
@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayoutCompute(t *testing.T) {
+	layout := Layout{Rows: []LayoutRow{
+		{Lines: 2, Panels: []LayoutPanel{{Name: "activity"}}},
+		{Weight: 1, Panels: []LayoutPanel{{Name: PanelCPUGraph, Weight: 3}, {Name: PanelMemory}}},
+		{Weight: 3, Panels: []LayoutPanel{{Name: PanelCPUCores}}},
+		{Panels: []LayoutPanel{{Name: "psi"}}},
+		{Panels: []LayoutPanel{{Name: PanelProcesses, Hidden: true}}},
+		{Panels: []LayoutPanel{{Name: PanelMemory}}},
+	}}
+	exists := func(name string) bool { return name != "psi" }
+	measure := func(c cell) int { return 8 }
+
+	cells := layout.compute(101, 50, exists, measure)
+
+	assert.Equal(t, [][]cell{
+		{{name: "activity", width: 101, height: 2}},
+		{{name: PanelCPUGraph, width: 75, height: 10}, {name: PanelMemory, width: 26, height: 10}},
+		{{name: PanelCPUCores, width: 101, height: 30}},
+		{{name: PanelMemory, width: 101}},
+	}, cells)
+}
+
+func TestLayoutPresetUnknown(t *testing.T) {
+	_, err := LayoutPreset("nope")
+	assert.Error(t, err)
+
+	layout, err := LayoutPreset("laptop")
+	assert.NoError(t, err)
+	hidden := layout.Hide(PanelMemory)
+	assert.True(t, hidden.Rows[0].Panels[1].Hidden)
+	assert.False(t, layout.Rows[0].Panels[1].Hidden)
+}
+
+func TestCheckPanelNames(t *testing.T) {
+	known := PanelNames([]Plugin{
+		{Name: "psi", NewPanel: func() Panel { return NewPSIPanel() }},
+		{Name: "activity", NewPanel: func() Panel { return NewSystemActivityHeader() }},
+		{Name: "cpu"}, // feeds the built-in views, has no panel of its own
+	})
+
+	for _, name := range LayoutPresets()["cpu-server"].Panels() {
+		assert.Contains(t, known, name)
+	}
+	assert.NoError(t, CheckPanelNames([]string{PanelMemory, "psi", PanelWatched}, known))
+	err := CheckPanelNames([]string{"cpu", "memroy", PanelMemory, "memroy"}, known)
+	assert.ErrorContains(t, err, "unknown panels [cpu memroy]")
+}
@@ -1,8 +1,13 @@
 package tui
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/NimbleMarkets/ntcharts/canvas/runes"
 	"github.com/NimbleMarkets/ntcharts/linechart/streamlinechart"
+	"github.com/NimbleMarkets/ntcharts/sparkline"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jonsampson/mim/internal/domain"
 )
@@ -24,8 +29,37 @@ var axisStyleMem = lipgloss.NewStyle().
 var labelStyleMem = lipgloss.NewStyle().
 	Foreground(lipgloss.Color("6")) // cyan
 
+// memorySegment is one slice of the stacked memory bar
+type memorySegment struct {
+	label string
+	style lipgloss.Style
+	value func(domain.MemoryBreakdown) uint64
+}
+
+var memorySegments = []memorySegment{
+	{"anon", lipgloss.NewStyle().Foreground(lipgloss.Color("5")), func(m domain.MemoryBreakdown) uint64 { return m.Anonymous }},
+	{"cache", lipgloss.NewStyle().Foreground(lipgloss.Color("4")), func(m domain.MemoryBreakdown) uint64 { return m.PageCache }},
+	{"buffers", lipgloss.NewStyle().Foreground(lipgloss.Color("6")), func(m domain.MemoryBreakdown) uint64 { return m.Buffers }},
+	{"shared", lipgloss.NewStyle().Foreground(lipgloss.Color("3")), func(m domain.MemoryBreakdown) uint64 { return m.Shared }},
+	{"slab", lipgloss.NewStyle().Foreground(lipgloss.Color("1")), func(m domain.MemoryBreakdown) uint64 { return m.Slab }},
+}
+
+var memoryFreeStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("8")) // gray
+
+// swapChartFloor is the swap traffic, in bytes per second, that fills the
+// swap sparkline until more is seen, so a trickle does not look like a storm
+const swapChartFloor = 1 << 20
+
 type MemoryUsageGraph struct {
-	slc streamlinechart.Model
+	focusState
+	slc       streamlinechart.Model
+	swapChart sparkline.Model
+	breakdown domain.MemoryBreakdown
+	numa      []domain.NUMANodeMemory
+	width     int
+	zoomed    bool
+	step      time.Duration // spacing of the samples, for the time axis
 }
 
 func NewMemoryUsageGraph() *MemoryUsageGraph {
@@ -43,7 +77,10 @@ func NewMemoryUsageGraph() *MemoryUsageGraph {
 	slc.DrawXYAxisAndLabel()
 
 	return &MemoryUsageGraph{
-		slc: slc,
+		slc:       slc,
+		swapChart: sparkline.New(10, 1, sparkline.WithMaxValue(swapChartFloor)),
+		width:     10,
+		step:      time.Second,
 	}
 }
 
@@ -58,18 +95,117 @@ func (g *MemoryUsageGraph) Update(msg any) {
 
 func (g *MemoryUsageGraph) updateSystemMemory(memoryMetrics domain.CPUMemoryMetrics) {
 	g.slc.PushDataSet(systemMemoryDataSet, memoryMetrics.MemoryUsage)
+	g.breakdown = memoryMetrics.Memory
+	g.numa = memoryMetrics.NUMAMemory
+	g.swapChart.Push(memoryMetrics.Memory.SwapInPerSec + memoryMetrics.Memory.SwapOutPerSec)
 }
 
 func (g *MemoryUsageGraph) updateGPUMemory(gpuMetrics domain.GPUMetrics) {
 	g.slc.PushDataSet(gpuMemoryDataSet, gpuMetrics.GPUMemoryUsage)
 }
 
+// SetZoomed adds a time axis below the graph while it fills the screen
+func (g *MemoryUsageGraph) SetZoomed(zoomed bool) {
+	g.zoomed = zoomed
+}
+
 func (g *MemoryUsageGraph) View() string {
 	g.slc.DrawAll()
-	return g.slc.View()
+	chart := g.slc.View()
+	if g.zoomed {
+		chart = lipgloss.JoinVertical(lipgloss.Left, chart, timeAxisView(g.slc.Width(), g.slc.Origin().X, g.step))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		chart,
+		g.breakdownView(),
+		g.swapView(),
+		g.numaView(),
+	)
+}
+
+// numaView renders per-node memory usage on multi-node machines
+func (g *MemoryUsageGraph) numaView() string {
+	if len(g.numa) < 2 {
+		return ""
+	}
+	parts := make([]string, 0, len(g.numa))
+	for _, node := range g.numa {
+		percent := 0.0
+		if node.Total > 0 {
+			percent = float64(node.Used) / float64(node.Total) * 100
+		}
+		parts = append(parts, fmt.Sprintf("node%d %.1f%% (%s/%s)",
+			node.Node, percent, formatBytes(node.Used), formatBytes(node.Total)))
+	}
+	return "NUMA " + strings.Join(parts, "  ")
+}
+
+// breakdownView renders a stacked bar of memory consumers followed by a legend
+func (g *MemoryUsageGraph) breakdownView() string {
+	total := g.breakdown.Total
+	if total == 0 {
+		return ""
+	}
+
+	barWidth := max(g.width, 1)
+	var bar strings.Builder
+	var legend []string
+	used := 0
+	for _, seg := range memorySegments {
+		value := seg.value(g.breakdown)
+		cells := int(float64(value) / float64(total) * float64(barWidth))
+		cells = min(cells, barWidth-used)
+		used += cells
+		bar.WriteString(seg.style.Render(strings.Repeat("█", cells)))
+		legend = append(legend, seg.style.Render(fmt.Sprintf("■ %s %s", seg.label, formatBytes(value))))
+	}
+	bar.WriteString(memoryFreeStyle.Render(strings.Repeat("░", barWidth-used)))
+	legend = append(legend, memoryFreeStyle.Render(fmt.Sprintf("░ free %s", formatBytes(g.breakdown.Free))))
+	legend = append(legend, fmt.Sprintf("dirty %s  writeback %s",
+		formatBytes(g.breakdown.Dirty), formatBytes(g.breakdown.Writeback)))
+
+	return lipgloss.JoinVertical(lipgloss.Left, bar.String(), g.fitWidth(strings.Join(legend, "  ")))
+}
+
+// swapView renders swap usage and a sparkline of swap traffic
+func (g *MemoryUsageGraph) swapView() string {
+	g.swapChart.Draw()
+	return g.fitWidth(fmt.Sprintf("Swap %s / %s  in %s/s  out %s/s  %s",
+		formatBytes(g.breakdown.SwapUsed),
+		formatBytes(g.breakdown.SwapTotal),
+		formatBytes(uint64(g.breakdown.SwapInPerSec)),
+		formatBytes(uint64(g.breakdown.SwapOutPerSec)),
+		graphLineStyleSystem.Render(g.swapChart.View()),
+	))
+}
+
+// fitWidth cuts a styled line to the panel width
+func (g *MemoryUsageGraph) fitWidth(line string) string {
+	return lipgloss.NewStyle().MaxWidth(max(g.width, 1)).Render(line)
+}
+
+// SampleAge reports how many samples back the column under x, y shows
+func (g *MemoryUsageGraph) SampleAge(x, y int) (int, bool) {
+	return streamAge(&g.slc, x, y)
 }
 
 func (g *MemoryUsageGraph) Resize(width, height int) {
 	g.slc.Resize(width, height)
+	g.width = width
+	g.swapChart.Resize(max(width/3, 1), 1)
+}
+
+// formatBytes renders a byte count using binary units
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ci", float64(b)/float64(div), "KMGTPE"[exp])
 }
 
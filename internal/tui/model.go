@@ -3,11 +3,15 @@ package tui
 import (
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jonsampson/mim/internal/domain"
+	"github.com/jonsampson/mim/internal/store"
 )
 
 // metricsCollector is a private interface that defines the behavior we expect from a metrics collector
@@ -17,26 +21,74 @@ type metricsCollector[T any] interface {
 	Metrics() <-chan T
 }
 
+// pluginPanel is a panel contributed by a plugin
+type pluginPanel struct {
+	name     string
+	panel    Panel
+	newPanel func() Panel
+}
+
+const (
+	defaultGraphHeight = 10
+	statusBarHeight    = 1
+
+	// Terminals smaller than this switch to the compact view
+	compactMaxWidth  = 60
+	compactMaxHeight = 15
+)
+
 type Model struct {
-	cpuGPUUsageGraph   *CPUGPUUsageGraph
-	memoryUsageGraph   *MemoryUsageGraph
-	cpuMemoryMetrics   domain.CPUMemoryMetrics
-	gpuMetrics         domain.GPUMetrics
-	cpuMemoryCollector metricsCollector[domain.CPUMemoryMetrics]
-	gpuCollector       metricsCollector[domain.GPUMetrics]
-	cpuUsagePerCore    []float64
-	cpuUsageTotal      float64
-	memoryUsage        float64
-	gpuUsage           float64
-	gpuMemoryUsage     float64
-	width              int
-	height             int
-	cpuCombinedView    *CPUCombinedView
-	processMonitor     *ProcessMonitor
-	viewport           viewport.Model
-}
-
-func InitialModel(collectors ...any) (Model, error) {
+	cpuGPUUsageGraph *CPUGPUUsageGraph
+	memoryUsageGraph *MemoryUsageGraph
+	cpuMemoryMetrics domain.CPUMemoryMetrics
+	gpuMetrics       domain.GPUMetrics
+	sources          map[string]Source
+	sourceOrder      []string
+	panels           []pluginPanel
+	layout           Layout
+	tabs             []tab
+	activeTab        int
+	focus            string // name of the focused panel
+	zoomed           bool   // focused panel fills the screen
+	cpuUsagePerCore  []float64
+	cpuUsageTotal    float64
+	memoryUsage      float64
+	gpuUsage         float64
+	gpuMemoryUsage   float64
+	width            int
+	height           int
+	cpuCombinedView  *CPUCombinedView
+	processMonitor   *ProcessMonitor
+	compactView      *CompactView
+	compact          bool // always use the compact view
+	processTables    map[string]*ProcessMonitor
+	processRanking   *processRanking // shared by processMonitor and processTables
+	watchPanel       *WatchPanel // nil without a watch list
+	viewport         viewport.Model
+	history          *metricsHistory
+	archive          *metricsHistory // samples loaded from the store, shown paused
+	archiveStep      time.Duration   // spacing of the archive's samples
+	paused           bool
+	pausedAt         time.Time     // newest message shown when the display froze
+	cursor           time.Time     // instant shown while paused
+	regions          []panelRegion // where each panel was last drawn
+	naturalHeights   map[string]int // natural-height panels measured by the last resize
+	selectedPID      uint32        // process highlighted by a click
+	tooltip          string        // graph value under the pointer
+	notice           string        // result of the last action, until the next key
+	keys             KeyMap
+	help             help.Model
+	showHelp         bool // help overlay replaces the panels
+	showStats        bool // statistics replace the panels
+	stats            *domain.RunStats
+	host             string              // remote host being monitored, empty when local
+	tree             *domain.ProcessTree // job the display is narrowed to, see SetProcessTree
+	job              string              // command of the job
+	observe          func(value any)
+}
+
+func InitialModel(plugins ...Plugin) (Model, error) {
+	ranking := newProcessRanking()
 	model := Model{
 		cpuUsagePerCore:  []float64{},
 		cpuUsageTotal:    0,
@@ -46,46 +98,128 @@ func InitialModel(collectors ...any) (Model, error) {
 		cpuGPUUsageGraph: NewCPUGPUUsageGraph(),
 		memoryUsageGraph: NewMemoryUsageGraph(),
 		cpuCombinedView:  NewCPUCombinedView(),
-		processMonitor:   NewProcessMonitor(80), // Initialize with a default width
-		width:            80,                    // Set a default width
-		height:           24,                    // Set a default height
+		processMonitor:   newProcessMonitor(80, ranking, allProcessTables...), // Initialize with a default width
+		compactView:      NewCompactView(),
+		tabs:             defaultTabs(),
+		sources:          make(map[string]Source),
+		width:            80, // Set a default width
+		height:           24, // Set a default height
 		viewport:         viewport.New(80, 24),
+		processTables:    newProcessTables(ranking),
+		processRanking:   ranking,
+		history:          newMetricsHistory(DefaultHistoryRetention),
+		stats:            domain.NewRunStats(),
+		keys:             DefaultKeyMap(),
+		help:             help.New(),
 	}
 
-	collectorInitialized := false
-
-	for _, c := range collectors {
-		switch collector := c.(type) {
-		case metricsCollector[domain.CPUMemoryMetrics]:
-			model.cpuMemoryCollector = collector
-			collector.Start()
-			collectorInitialized = true
-		case metricsCollector[domain.GPUMetrics]:
-			model.gpuCollector = collector
-			collector.Start()
-			collectorInitialized = true
-		default:
-			fmt.Printf("Unknown collector type: %T\n", c)
+	for _, plugin := range plugins {
+		if _, exists := model.sources[plugin.Name]; exists {
+			return Model{}, fmt.Errorf("collector %q provided twice", plugin.Name)
 		}
+		source := plugin.NewSource()
+		model.sources[plugin.Name] = source
+		model.sourceOrder = append(model.sourceOrder, plugin.Name)
+		if plugin.NewPanel != nil {
+			model.panels = append(model.panels, pluginPanel{
+				name:     plugin.Name,
+				panel:    plugin.NewPanel(),
+				newPanel: plugin.NewPanel,
+			})
+		}
+		source.Start()
 	}
 
-	if !collectorInitialized {
+	if len(model.sources) == 0 {
 		return Model{}, fmt.Errorf("no valid collectors provided")
 	}
 
 	return model, nil
 }
 
-func (m Model) Init() tea.Cmd {
-	var cmds []tea.Cmd
+// newProcessTables creates the single-purpose process tables used by tabs
+func newProcessTables(ranking *processRanking) map[string]*ProcessMonitor {
+	return map[string]*ProcessMonitor{
+		PanelCPUProcesses:    newProcessMonitor(80, ranking, processCPU),
+		PanelMemoryProcesses: newProcessMonitor(80, ranking, processMem),
+		PanelIOProcesses:     newProcessMonitor(80, ranking, processIORead, processIOWrite),
+		PanelGPUProcesses:    newProcessMonitor(80, ranking, processGPU, processGPUMem),
+	}
+}
+
+// SetHistoryRetention sets how far back a paused display can be scrubbed
+func (m *Model) SetHistoryRetention(retention time.Duration) {
+	m.history = newMetricsHistory(retention)
+}
 
-	if m.cpuMemoryCollector != nil {
-		cmds = append(cmds, listenForMetrics(m.cpuMemoryCollector.Metrics()))
+// ShowArchive starts the display paused on samples loaded from the on-disk
+// store, step apart. Scrubbing moves through them; resuming returns to the
+// live metrics.
+func (m *Model) ShowArchive(samples []store.Sample, step time.Duration) {
+	if len(samples) == 0 {
+		return
 	}
+	m.archive = newArchiveHistory(samples, step)
+	m.archiveStep = step
+	m.paused = true
+	m.pausedAt = m.archive.newest()
+	m.cursor = m.pausedAt
+}
 
-	if m.gpuCollector != nil {
-		cmds = append(cmds, listenForMetrics(m.gpuCollector.Metrics()))
+// shownHistory is what the paused display steps through, and the spacing of
+// its samples
+func (m Model) shownHistory() (*metricsHistory, time.Duration) {
+	if m.archive != nil {
+		return m.archive, m.archiveStep
 	}
+	return m.history, time.Second
+}
+
+// SetKeyMap replaces the key bindings
+func (m *Model) SetKeyMap(keys KeyMap) {
+	m.keys = keys
+}
+
+// SetHost names the remote host whose metrics are shown
+func (m *Model) SetHost(host string) {
+	m.host = host
+}
+
+// SetProcessTree narrows the process tables, graphs and statistics to a
+// process and its descendants, labelled with the job's command. Observers
+// still receive the machine-wide values.
+func (m *Model) SetProcessTree(tree *domain.ProcessTree, command string) {
+	m.tree = tree
+	m.job = command
+}
+
+// SetWatchList shows the watched processes in their own panel, at the top
+// of the overview and the processes screen
+func (m *Model) SetWatchList(watch *domain.WatchList) {
+	m.watchPanel = NewWatchPanel(watch)
+}
+
+// SetMetricsObserver passes every collector value to observe as it
+// arrives, for example to export it
+func (m *Model) SetMetricsObserver(observe func(value any)) {
+	m.observe = observe
+}
+
+// SetCompact forces the compact view regardless of the terminal size
+func (m *Model) SetCompact(compact bool) {
+	m.compact = compact
+	m.resizePanels()
+}
+
+// SetLayout replaces the panel arrangement. Panels that are not running are
+// skipped; plugin panels missing from the layout are appended at the bottom.
+func (m *Model) SetLayout(layout Layout) {
+	m.layout = layout
+	m.resizePanels()
+}
+
+func (m Model) Init() tea.Cmd {
+	cmds := m.listenAll()
 
 	// Return a command to get the initial window size
 	cmds = append(cmds, tea.EnterAltScreen)
@@ -93,103 +227,769 @@ func (m Model) Init() tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
+// listenAll waits for the next value of every source
+func (m Model) listenAll() []tea.Cmd {
+	var cmds []tea.Cmd
+	for _, name := range m.sourceOrder {
+		cmds = append(cmds, m.sources[name].listen(name))
+	}
+	return cmds
+}
+
+// stop stops every source
+func (m Model) stop() {
+	for _, name := range m.sourceOrder {
+		m.sources[name].Stop()
+	}
+}
+
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
-			m.cpuMemoryCollector.Stop()
-			if m.gpuCollector != nil {
-				m.gpuCollector.Stop()
-			}
-			return m, tea.Quit
-		case "up", "k":
-			m.viewport.LineUp(1)
-		case "down", "j":
-			m.viewport.LineDown(1)
-		case "pgup":
-			m.viewport.HalfViewUp()
-		case "pgdown":
-			m.viewport.HalfViewDown()
-		case "home":
-			m.viewport.GotoTop()
-		case "end":
-			m.viewport.GotoBottom()
-			return m, tea.Quit
-		}
+		return m.handleKey(msg)
+
+	case tea.MouseMsg:
+		m.handleMouse(msg)
 
 	case tea.WindowSizeMsg:
 		log.Printf("Window size changed: %d x %d", msg.Width, msg.Height)
 		m.width = msg.Width
 		m.height = msg.Height
-		m.processMonitor.Resize(m.width) // Add this method to ProcessMonitor
-		m.cpuCombinedView.Resize(m.width-5, m.height)
-		m.cpuGPUUsageGraph.Resize(m.width-5, 10)
-		m.memoryUsageGraph.Resize(m.width-5, 10)
-		m.viewport.Height = m.height
+		m.resizePanels()
+		m.refreshContent()
+		m.viewport.Height = m.bodyHeight()
 		m.viewport.Width = m.width
+
+	case metricsMsg:
+		value := msg.value
+		if m.tree != nil {
+			value = m.tree.Focus(value)
+		}
+		// Collection continues while paused; the display catches up on resume
+		if m.history != nil {
+			m.history.add(time.Now(), msg.source, value)
+		}
+		if m.stats != nil {
+			m.stats.Add(value)
+		}
+		if m.observe != nil {
+			m.observe(msg.value)
+		}
+		if !m.paused {
+			m.updateMetrics(value)
+			m.refreshContent()
+		} else if m.showStats {
+			m.refreshContent()
+		}
+		if source, ok := m.sources[msg.source]; ok {
+			cmd = source.listen(msg.source)
+		}
+	}
+
+	return m, cmd
+}
+
+func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keys.Quit) {
+		m.stop()
+		return m, tea.Quit
+	}
+
+	m.notice = ""
+
+	// The help overlay takes all other keys until it is closed. The compact
+	// view draws neither overlay, so it leaves the keys alone.
+	if m.showHelp && !m.isCompact() {
+		if key.Matches(msg, m.keys.Help, m.keys.Unzoom) {
+			m.showHelp = false
+		}
+		return m, nil
+	}
+
+	switch {
+	case m.isCompact() && key.Matches(msg, m.keys.Help, m.keys.Stats):
+	case key.Matches(msg, m.keys.Help):
+		m.showHelp = true
+	case key.Matches(msg, m.keys.Stats):
+		m.setShowStats(!m.showStats)
+	case m.showStats && key.Matches(msg, m.keys.Unzoom):
+		m.setShowStats(false)
+	case key.Matches(msg, m.keys.Up):
+		m.viewport.LineUp(1)
+	case key.Matches(msg, m.keys.Down):
+		m.viewport.LineDown(1)
+	case key.Matches(msg, m.keys.PageUp):
+		m.viewport.HalfViewUp()
+	case key.Matches(msg, m.keys.PageDown):
+		m.viewport.HalfViewDown()
+	case key.Matches(msg, m.keys.Top):
+		m.viewport.GotoTop()
+	case key.Matches(msg, m.keys.Bottom):
+		m.viewport.GotoBottom()
+	case key.Matches(msg, m.keys.Breakdown):
+		m.cpuGPUUsageGraph.ToggleBreakdown()
+		m.refreshContent()
+	case key.Matches(msg, m.keys.HeatmapMetric):
+		m.cpuCombinedView.CycleHeatmapMetric()
+		m.refreshContent()
+	case key.Matches(msg, m.keys.Frequency):
+		m.cpuCombinedView.ToggleFrequency()
+		m.refreshContent()
+	case key.Matches(msg, m.keys.Pause):
+		m.setPaused(!m.paused)
+	case key.Matches(msg, m.keys.StepBack):
+		m.scrub(-time.Second)
+	case key.Matches(msg, m.keys.StepForward):
+		m.scrub(time.Second)
+	case key.Matches(msg, m.keys.JumpBack):
+		m.scrub(-10 * time.Second)
+	case key.Matches(msg, m.keys.JumpForward):
+		m.scrub(10 * time.Second)
+	case key.Matches(msg, m.keys.ExportHistory):
+		m.exportHistory()
+	case key.Matches(msg, m.keys.NextFocus):
+		m.cycleFocus(1)
+		m.refreshContent()
+	case key.Matches(msg, m.keys.PrevFocus):
+		m.cycleFocus(-1)
+		m.refreshContent()
+	case key.Matches(msg, m.keys.Zoom):
+		m.setZoomed(!m.zoomed)
+	case key.Matches(msg, m.keys.Unzoom):
+		m.setZoomed(false)
+	case key.Matches(msg, m.keys.Screen):
+		// The nth key of the binding selects the nth screen
+		for i, k := range m.keys.Screen.Keys() {
+			if k == msg.String() {
+				m.selectTab(i)
+			}
+		}
+	case key.Matches(msg, m.keys.NextScreen):
+		m.selectTab((m.activeTab + 1) % max(len(m.tabs), 1))
+	case key.Matches(msg, m.keys.PrevScreen):
+		m.selectTab((m.activeTab + len(m.tabs) - 1) % max(len(m.tabs), 1))
+	}
+	return m, nil
+}
+
+// updateMetrics feeds a collector value to the built-in views and to every
+// plugin panel
+func (m *Model) updateMetrics(value any) {
+	switch msg := value.(type) {
 	case domain.CPUMemoryMetrics:
 		m.cpuMemoryMetrics = msg
 		m.cpuUsagePerCore = msg.CPUUsagePerCore
 		m.cpuUsageTotal = msg.CPUUsageTotal
 		m.memoryUsage = msg.MemoryUsage
 
-		m.cpuCombinedView.Update(msg)
-		m.cpuGPUUsageGraph.Update(msg)
-		m.memoryUsageGraph.Update(msg)
-
-		m.processMonitor.UpdateProcesses(m.cpuMemoryMetrics.Processes, m.gpuMetrics.Processes)
-
-		m.viewport.SetContent(m.renderContent())
-		cmd = listenForMetrics(m.cpuMemoryCollector.Metrics())
-
 	case domain.GPUMetrics:
 		m.gpuMetrics = msg
 		m.gpuUsage = msg.GPUUsage
 		m.gpuMemoryUsage = msg.GPUMemoryUsage
-		m.cpuGPUUsageGraph.Update(msg)
-		m.memoryUsageGraph.Update(msg)
+	}
 
-		m.processMonitor.UpdateProcesses(m.cpuMemoryMetrics.Processes, m.gpuMetrics.Processes)
+	// Sorted once here for all the process tables
+	if m.processRanking != nil {
+		m.processRanking.Update(value)
+	}
+	for _, panel := range m.allPanels() {
+		panel.Update(value)
+	}
+}
+
+// allPanels returns the built-in views, process tables and plugin panels
+func (m Model) allPanels() []Panel {
+	var panels []Panel
+	for _, name := range []string{PanelCPUGraph, PanelCPUCores, PanelMemory, PanelProcesses, PanelCompact, PanelWatched} {
+		if panel := m.panel(name); panel != nil {
+			panels = append(panels, panel)
+		}
+	}
+	for _, pm := range m.processTables {
+		panels = append(panels, pm)
+	}
+	for _, p := range m.panels {
+		panels = append(panels, p.panel)
+	}
+	return panels
+}
 
-		m.viewport.SetContent(m.renderContent())
-		cmd = listenForMetrics(m.gpuCollector.Metrics())
+// panel looks up a panel by its layout name
+func (m Model) panel(name string) Panel {
+	switch name {
+	case PanelCPUGraph:
+		if m.cpuGPUUsageGraph != nil {
+			return m.cpuGPUUsageGraph
+		}
+	case PanelCPUCores:
+		if m.cpuCombinedView != nil {
+			return m.cpuCombinedView
+		}
+	case PanelMemory:
+		if m.memoryUsageGraph != nil {
+			return m.memoryUsageGraph
+		}
+	case PanelProcesses:
+		if m.processMonitor != nil {
+			return m.processMonitor
+		}
+	case PanelCompact:
+		if m.compactView != nil {
+			return m.compactView
+		}
+	case PanelWatched:
+		if m.watchPanel != nil {
+			return m.watchPanel
+		}
+	default:
+		if pm, ok := m.processTables[name]; ok {
+			return pm
+		}
+		for _, p := range m.panels {
+			if p.name == name {
+				return p.panel
+			}
+		}
 	}
+	return nil
+}
 
-	return m, cmd
+// selectTab switches screens, resizing panels for the new layout
+func (m *Model) selectTab(index int) {
+	if index < 0 || index >= len(m.tabs) || index == m.activeTab {
+		return
+	}
+	m.setZoomed(false)
+	m.activeTab = index
+	m.refreshLayout()
+}
+
+// refreshLayout resizes and re-renders panels after the layout changed
+func (m *Model) refreshLayout() {
+	m.resizePanels()
+	m.refreshContent()
+	m.viewport.GotoTop()
+}
+
+// refreshContent re-renders the viewport, from history when paused
+func (m *Model) refreshContent() {
+	var content string
+	if m.showStats && !m.isCompact() {
+		content, m.regions = m.statsView(), nil
+	} else if m.paused {
+		replay := m.replayAt(m.cursor)
+		content, m.regions, _ = replay.renderRegions()
+	} else {
+		var stale bool
+		content, m.regions, stale = m.renderRegions()
+		if stale {
+			// A natural-height panel grew or shrank, so refit the weighted rows
+			m.resizePanels()
+			content, m.regions, _ = m.renderRegions()
+		}
+	}
+	m.viewport.SetContent(content)
+}
+
+// setShowStats swaps the panels for the statistics overlay and back
+func (m *Model) setShowStats(show bool) {
+	m.showStats = show
+	m.refreshContent()
+	m.viewport.GotoTop()
+}
+
+// RunStats returns the statistics of every sample received so far
+func (m Model) RunStats() *domain.RunStats {
+	return m.stats
+}
+
+// setPaused freezes the display at the newest message or resumes live
+// updates, first applying everything received while paused
+func (m *Model) setPaused(paused bool) {
+	if m.history == nil || paused == m.paused {
+		return
+	}
+	if paused {
+		m.pausedAt = m.history.newest()
+		m.cursor = m.pausedAt
+	} else {
+		// The live panels were never updated while an archive was shown
+		if m.archive != nil {
+			m.archive = nil
+			m.pausedAt = time.Time{}
+		}
+		for _, entry := range m.history.between(m.pausedAt, m.history.newest()) {
+			m.updateMetrics(entry.value)
+		}
+	}
+	m.paused = paused
+	m.refreshContent()
+}
+
+// scrub moves the paused display through the retained history, by as many
+// samples as step is seconds
+func (m *Model) scrub(step time.Duration) {
+	if !m.paused {
+		return
+	}
+	history, spacing := m.shownHistory()
+	cursor := m.cursor.Add(time.Duration(step.Seconds() * float64(spacing)))
+	if oldest := history.oldest(); cursor.Before(oldest) {
+		cursor = oldest
+	}
+	if newest := history.newest(); cursor.After(newest) {
+		cursor = newest
+	}
+	m.cursor = cursor
+	m.refreshContent()
+}
+
+// replayAt returns a copy of the model whose panels are rebuilt from the
+// history up to the given instant. View settings of the live panels carry
+// over; only as many samples as the widest graph can show are replayed.
+func (m Model) replayAt(at time.Time) Model {
+	history, spacing := m.shownHistory()
+	replay := m
+	replay.cpuGPUUsageGraph = NewCPUGPUUsageGraph()
+	replay.cpuGPUUsageGraph.showBreakdown = m.cpuGPUUsageGraph.showBreakdown
+	replay.cpuGPUUsageGraph.step = spacing
+	replay.memoryUsageGraph = NewMemoryUsageGraph()
+	replay.memoryUsageGraph.step = spacing
+	replay.cpuCombinedView = NewCPUCombinedView()
+	replay.cpuCombinedView.heatmap.metricIndex = m.cpuCombinedView.heatmap.metricIndex
+	replay.cpuCombinedView.busiestCores.showFrequency = m.cpuCombinedView.busiestCores.showFrequency
+	replay.processRanking = newProcessRanking()
+	replay.processMonitor = newProcessMonitor(m.width, replay.processRanking, allProcessTables...)
+	replay.compactView = NewCompactView()
+	replay.processTables = newProcessTables(replay.processRanking)
+	if m.watchPanel != nil {
+		replay.watchPanel = NewWatchPanel(m.watchPanel.watch.Empty())
+	}
+	replay.panels = make([]pluginPanel, len(m.panels))
+	for i, p := range m.panels {
+		replay.panels[i] = pluginPanel{name: p.name, panel: p.newPanel(), newPanel: p.newPanel}
+	}
+
+	replay.selectProcess(m.selectedPID)
+	replay.focus = ""
+	replay.setFocus(m.focus)
+	if z, ok := replay.panel(m.focus).(Zoomable); ok && m.zoomed {
+		z.SetZoomed(true)
+	}
+
+	// Size first: streaming charts only keep as many samples as they are wide
+	replay.resizePanels()
+	window := time.Duration(m.width) * spacing
+	for _, entry := range history.between(at.Add(-window), at) {
+		replay.updateMetrics(entry.value)
+	}
+	return replay
+}
+
+// exportHistory writes the retained history to CSV files in the working
+// directory and reports the result in the status bar
+func (m *Model) exportHistory() {
+	if m.history == nil || m.history.newest().IsZero() {
+		m.notice = "no history to export"
+		return
+	}
+	base := "mim-history-" + time.Now().Format("20060102-150405")
+	rows, err := m.history.writeCSV(base+".csv", base+"-processes.csv")
+	if err != nil {
+		log.Printf("Error exporting history: %v", err)
+		m.notice = fmt.Sprintf("export failed: %v", err)
+		return
+	}
+	m.notice = fmt.Sprintf("exported %d samples to %s.csv and %s-processes.csv", rows, base, base)
+}
+
+// cycleFocus moves focus to the next or previous visible panel
+func (m *Model) cycleFocus(delta int) {
+	var names []string
+	for _, row := range m.cells(nil) {
+		for _, c := range row {
+			names = append(names, c.name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	next := 0
+	if delta < 0 {
+		next = len(names) - 1
+	}
+	for i, name := range names {
+		if name == m.focus {
+			next = (i + delta + len(names)) % len(names)
+			break
+		}
+	}
+	m.setFocus(names[next])
+}
+
+func (m *Model) setFocus(name string) {
+	if panel := m.panel(m.focus); panel != nil {
+		panel.Blur()
+	}
+	m.focus = name
+	if panel := m.panel(name); panel != nil {
+		panel.Focus()
+	}
+}
+
+// setZoomed expands the focused panel to the whole screen or restores the
+// layout. Zooming without a focused panel focuses the first one.
+func (m *Model) setZoomed(zoomed bool) {
+	if zoomed == m.zoomed {
+		return
+	}
+	if zoomed && m.panel(m.focus) == nil {
+		m.cycleFocus(1)
+		if m.panel(m.focus) == nil {
+			return
+		}
+	}
+
+	m.zoomed = zoomed
+	if z, ok := m.panel(m.focus).(Zoomable); ok {
+		z.SetZoomed(zoomed)
+	}
+	m.refreshLayout()
 }
 
 func (m Model) View() string {
-	return fmt.Sprintf("%s\n%s", m.viewport.View(), m.statusBarView())
+	if m.isCompact() {
+		return m.viewport.View()
+	}
+
+	body := m.viewport.View()
+	if m.showHelp {
+		body = m.helpView()
+	}
+	return fmt.Sprintf("%s\n%s\n%s", tabBarView(m.tabs, m.activeTab, m.barLabel()), body, m.statusBarView())
 }
 
-// Add a new method to render the content
-func (m Model) renderContent() string {
-	// Render components
-	cpuSection := m.cpuCombinedView.View()
-
-	// Combine columns
-	content := lipgloss.JoinVertical(
-		lipgloss.Top, "\n", // add spacing for viewport
-		m.cpuGPUUsageGraph.View(),
-		fmt.Sprintf("    CPU Usage: %.2f%%   GPU Usage: %.2f%%", m.cpuUsageTotal, m.gpuUsage),
-		lipgloss.NewStyle().Margin(0).Render(""),
-		cpuSection,
-		m.memoryUsageGraph.View(),
-		fmt.Sprintf("    Memory Usage: %.2f%%   GPU Memory Usage: %.2f%%", m.memoryUsage, m.gpuMemoryUsage),
-		m.processMonitor.View(),
-	)
+// barLabel names the remote host and the job shown, if any
+func (m Model) barLabel() string {
+	if m.tree == nil {
+		return m.host
+	}
+	processes := "processes"
+	if m.tree.Len() == 1 {
+		processes = "process"
+	}
+	job := fmt.Sprintf("job: %s (%d %s)", m.job, m.tree.Len(), processes)
+	if m.host == "" {
+		return job
+	}
+	return m.host + "  " + job
+}
+
+// helpView lists every key binding in place of the panels
+func (m Model) helpView() string {
+	h := m.help
+	h.ShowAll = true
+	h.Width = m.width - 6 // border and padding
+	return lipgloss.Place(m.width, m.bodyHeight(), lipgloss.Center, lipgloss.Center,
+		lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(1, 2).Render(h.View(m.keys)))
+}
+
+// bodyHeight is the terminal height left for panels
+func (m Model) bodyHeight() int {
+	if m.isCompact() {
+		return m.height
+	}
+	return m.height - tabBarHeight - statusBarHeight
+}
+
+// bodyTop is the screen line where the panels start
+func (m Model) bodyTop() int {
+	if m.isCompact() {
+		return 0
+	}
+	return tabBarHeight
+}
+
+// isCompact reports whether the compact view replaces the screens, either
+// by request or because the terminal is small
+func (m Model) isCompact() bool {
+	if m.compactView == nil {
+		return false
+	}
+	return m.compact || m.width < compactMaxWidth || m.height < compactMaxHeight
+}
+
+func (m Model) onOverview() bool {
+	return m.activeTab == 0 || m.activeTab >= len(m.tabs)
+}
 
+// activeLayout returns the layout of the current tab. On the overview,
+// plugin panels that no layout mentions are appended as full-width rows.
+func (m Model) activeLayout() Layout {
+	if m.isCompact() {
+		return Layout{Rows: []LayoutRow{{Weight: 1, Panels: []LayoutPanel{{Name: PanelCompact}}}}}
+	}
+	if m.zoomed {
+		return Layout{Rows: []LayoutRow{{Weight: 1, Panels: []LayoutPanel{{Name: m.focus}}}}}
+	}
+	if !m.onOverview() {
+		return m.tabs[m.activeTab].layout
+	}
+
+	layout := m.layout
+	if len(layout.Rows) == 0 {
+		layout = DefaultLayout()
+	}
+
+	placed := make(map[string]bool)
+	for _, l := range append([]Layout{layout}, tabLayouts(m.tabs)...) {
+		for _, row := range l.Rows {
+			for _, panel := range row.Panels {
+				placed[panel.Name] = true
+			}
+		}
+	}
+	var rows []LayoutRow
+	if !mentions(layout, PanelWatched) {
+		rows = append(rows, LayoutRow{Panels: []LayoutPanel{{Name: PanelWatched}}})
+	}
+	rows = append(rows, layout.Rows...)
+	for _, p := range m.panels {
+		if !placed[p.name] {
+			rows = append(rows, LayoutRow{Panels: []LayoutPanel{{Name: p.name}}})
+		}
+	}
+	return Layout{Rows: rows}
+}
+
+// mentions reports whether a layout places the named panel
+func mentions(layout Layout, name string) bool {
+	for _, row := range layout.Rows {
+		for _, panel := range row.Panels {
+			if panel.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func tabLayouts(tabs []tab) []Layout {
+	layouts := make([]Layout, 0, len(tabs))
+	for _, t := range tabs {
+		layouts = append(layouts, t.layout)
+	}
+	return layouts
+}
+
+// cells lays out the active screen. Each cell is the panel's own area,
+// without the focus gutter drawn to its left.
+func (m Model) cells(measure func(c cell) int) [][]cell {
+	gutter := m.focusGutter()
+	inner := func(c cell) cell {
+		c.width = max(c.width-gutter, 0)
+		return c
+	}
+	var measureInner func(c cell) int
+	if measure != nil {
+		measureInner = func(c cell) int { return measure(inner(c)) }
+	}
+	rows := m.activeLayout().compute(m.width, m.bodyHeight(), m.hasPanel, measureInner)
+	for _, row := range rows {
+		for j := range row {
+			row[j] = inner(row[j])
+		}
+	}
+	return rows
+}
+
+// focusGutter is the width of the focus gutter; a zoomed or compact screen
+// has only one panel and needs none
+func (m Model) focusGutter() int {
+	if m.zoomed || m.isCompact() {
+		return 0
+	}
+	return focusGutterWidth
+}
+
+// panelHeight measures a panel rendered at its current size
+func (m Model) panelHeight(c cell) int {
+	return lipgloss.Height(m.renderPanel(c.name))
+}
+
+func (m Model) hasPanel(name string) bool {
+	return m.panel(name) != nil
+}
+
+// measuredHeight returns the height of a natural-height panel as measured
+// by the last resize, measuring it only if it was not
+func (m Model) measuredHeight(c cell) int {
+	if height, ok := m.naturalHeights[c.name]; ok {
+		return height
+	}
+	return m.panelHeight(c)
+}
+
+// resizePanels sizes every panel to its layout cell. Natural-height panels
+// are sized first so weighted rows can be fitted around them; their heights
+// are kept for rendering until the next resize.
+func (m *Model) resizePanels() {
+	heights := make(map[string]int)
+	measure := func(c cell) int {
+		m.resizePanel(c)
+		heights[c.name] = m.panelHeight(c)
+		return heights[c.name]
+	}
+	for _, row := range m.cells(measure) {
+		for _, c := range row {
+			m.resizePanel(c)
+		}
+	}
+	m.naturalHeights = heights
+}
+
+func (m *Model) resizePanel(c cell) {
+	panel := m.panel(c.name)
+	if panel == nil {
+		return
+	}
+
+	// The time axis shown while zoomed takes a line below the chart
+	axisLines := 0
+	if m.zoomed {
+		axisLines = 1
+	}
+
+	switch c.name {
+	case PanelCPUGraph:
+		// usage line and spacer below the graph
+		panel.Resize(c.width-5, graphHeight(c.height, 2+axisLines))
+	case PanelMemory:
+		// usage line, stacked bar, legend, swap and NUMA lines
+		panel.Resize(c.width-5, graphHeight(c.height, 5+axisLines))
+	case PanelCPUCores:
+		height := c.height
+		if height == 0 {
+			height = m.height
+		}
+		panel.Resize(c.width-5, height)
+	default:
+		panel.Resize(c.width, c.height)
+	}
+}
+
+// graphHeight returns the chart height for a cell, leaving room for the
+// given number of text lines; natural cells get the default height
+func graphHeight(cellHeight, textLines int) int {
+	if cellHeight == 0 {
+		return defaultGraphHeight
+	}
+	return max(cellHeight-textLines, 3)
+}
+
+// renderContent renders all panels according to the layout
+func (m Model) renderContent() string {
+	content, _, _ := m.renderRegions()
 	return content
 }
 
-func listenForMetrics[T any](metrics <-chan T) tea.Cmd {
-	return func() tea.Msg {
-		return <-metrics
+// renderRegions renders the content and records where each panel landed,
+// for mapping mouse positions back to panels. stale reports that a
+// natural-height panel no longer has the height the layout was fitted to.
+func (m Model) renderRegions() (content string, regions []panelRegion, stale bool) {
+	var rows []string
+	y := 0
+	if m.onOverview() && !m.zoomed && !m.isCompact() {
+		rows = append(rows, "\n") // add spacing for viewport
+		y = lipgloss.Height(rows[0])
 	}
+	for _, row := range m.cells(m.measuredHeight) {
+		views := make([]string, 0, len(row))
+		x := 0
+		for _, c := range row {
+			rendered := m.renderPanel(c.name)
+			if height, ok := m.naturalHeights[c.name]; ok && c.height == 0 && lipgloss.Height(rendered) != height {
+				stale = true
+			}
+			view := fitCell(rendered, c)
+			width, height := lipgloss.Size(view)
+			if gutter := m.focusGutter(); gutter > 0 {
+				view = lipgloss.JoinHorizontal(lipgloss.Top, focusGutterView(m.panel(c.name), height), view)
+				x += gutter
+			}
+			views = append(views, view)
+			regions = append(regions, panelRegion{name: c.name, x: x, y: y, width: width, height: height})
+			x += width
+		}
+		joined := lipgloss.JoinHorizontal(lipgloss.Top, views...)
+		rows = append(rows, joined)
+		y += lipgloss.Height(joined)
+	}
+
+	// Combine rows
+	return lipgloss.JoinVertical(lipgloss.Top, rows...), regions, stale
+}
+
+func (m Model) renderPanel(name string) string {
+	panel := m.panel(name)
+	if panel == nil {
+		return ""
+	}
+
+	switch name {
+	case PanelCPUGraph:
+		return lipgloss.JoinVertical(lipgloss.Top,
+			panel.View(),
+			fmt.Sprintf("    CPU Usage: %.2f%%   GPU Usage: %.2f%%", m.cpuUsageTotal, m.gpuUsage),
+			lipgloss.NewStyle().Margin(0).Render(""),
+		)
+	case PanelMemory:
+		return lipgloss.JoinVertical(lipgloss.Top,
+			panel.View(),
+			fmt.Sprintf("    Memory Usage: %.2f%%   GPU Memory Usage: %.2f%%", m.memoryUsage, m.gpuMemoryUsage),
+		)
+	}
+	return panel.View()
 }
 
 func (m Model) statusBarView() string {
-	return fmt.Sprintf("Press q to quit | Scroll: ↑/↓ or mouse | %3.f%%", m.viewport.ScrollPercent()*100)
+	if m.paused {
+		resume := m.keys.Pause
+		resume.SetHelp(resume.Help().Key, "resume")
+		if m.archive != nil {
+			// Steps move by samples, which are further apart than a second
+			bindings := []key.Binding{m.keys.StepBack, m.keys.StepForward, m.keys.JumpBack, m.keys.JumpForward, resume}
+			for i, help := range []string{"back 1 sample", "forward 1 sample", "back 10 samples", "forward 10 samples", "go live"} {
+				bindings[i].SetHelp(bindings[i].Help().Key, help)
+			}
+			return fmt.Sprintf("STORED %s (%s samples) | %s | %3.f%%",
+				m.cursor.Format("2006-01-02 15:04:05"),
+				m.archiveStep,
+				m.help.ShortHelpView(bindings),
+				m.viewport.ScrollPercent()*100)
+		}
+		return fmt.Sprintf("PAUSED at %s (%s ago) | %s | %3.f%%",
+			m.cursor.Format("15:04:05"),
+			m.history.newest().Sub(m.cursor).Round(time.Second),
+			m.help.ShortHelpView([]key.Binding{m.keys.StepBack, m.keys.StepForward, m.keys.JumpBack, m.keys.JumpForward, resume}),
+			m.viewport.ScrollPercent()*100)
+	}
+
+	if m.tooltip != "" {
+		return fmt.Sprintf("%s | %3.f%%", m.tooltip, m.viewport.ScrollPercent()*100)
+	}
+	if m.notice != "" {
+		return fmt.Sprintf("%s | %3.f%%", m.notice, m.viewport.ScrollPercent()*100)
+	}
+
+	status := m.help.ShortHelpView(m.keys.ShortHelp())
+	if m.focus != "" {
+		status += fmt.Sprintf(" | focus: %s", m.focus)
+	}
+	if m.selectedPID != 0 {
+		status += fmt.Sprintf(" | selected: %d", m.selectedPID)
+	}
+	return fmt.Sprintf("%s | %3.f%%", status, m.viewport.ScrollPercent()*100)
 }
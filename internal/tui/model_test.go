@@ -1,10 +1,18 @@
 package tui
 
 import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/jonsampson/mim/internal/domain"
+	"github.com/jonsampson/mim/internal/store"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,8 +27,10 @@ func TestModelUpdate(t *testing.T) {
 	mockGPUCollector.On("Metrics").Return(gpuMetricsChan)
 
 	model := Model{
-		cpuMemoryCollector: mockCPUMemoryCollector,
-		gpuCollector:       mockGPUCollector,
+		sources: map[string]Source{
+			"cpu":    NewSource[domain.CPUMemoryMetrics](mockCPUMemoryCollector),
+			"nvidia": NewSource[domain.GPUMetrics](mockGPUCollector),
+		},
 		cpuCombinedView:    NewCPUCombinedView(),
 		cpuGPUUsageGraph:   NewCPUGPUUsageGraph(),
 		memoryUsageGraph:   NewMemoryUsageGraph(),
@@ -33,7 +43,7 @@ func TestModelUpdate(t *testing.T) {
 			MemoryUsage:     50.0,
 		}
 
-		updatedModel, cmd := model.Update(cpuMemoryMetrics)
+		updatedModel, cmd := model.Update(metricsMsg{source: "cpu", value: cpuMemoryMetrics})
 		updatedModelTyped := updatedModel.(Model)
 
 		assert.Equal(t, cpuMemoryMetrics.CPUUsagePerCore, updatedModelTyped.cpuUsagePerCore)
@@ -48,7 +58,7 @@ func TestModelUpdate(t *testing.T) {
 			GPUMemoryUsage: 80.0,
 		}
 
-		updatedModel, cmd := model.Update(gpuMetrics)
+		updatedModel, cmd := model.Update(metricsMsg{source: "nvidia", value: gpuMetrics})
 		updatedModelTyped := updatedModel.(Model)
 
 		assert.Equal(t, gpuMetrics.GPUUsage, updatedModelTyped.gpuUsage)
@@ -73,6 +83,7 @@ func TestModelView(t *testing.T) {
         memoryUsageGraph: NewMemoryUsageGraph(),
         processMonitor:   NewProcessMonitor(80),
         viewport:         viewport.New(80, 50),
+        keys:             DefaultKeyMap(),
     }
 
     // Set up the viewport with the rendered content
@@ -84,7 +95,7 @@ func TestModelView(t *testing.T) {
     assert.Contains(t, view, "Memory Usage: 50.00%")
     assert.Contains(t, view, "GPU Usage: 70.00%")
     assert.Contains(t, view, "GPU Memory Usage: 80.00%")
-    assert.Contains(t, view, "Press q to quit")
+    assert.Contains(t, view, "q quit")
 }
 
 func TestInitialModel(t *testing.T) {
@@ -94,11 +105,14 @@ func TestInitialModel(t *testing.T) {
 	mockCPUMemoryCollector.On("Start").Return()
 	mockGPUCollector.On("Start").Return()
 
-	model, err := InitialModel(mockCPUMemoryCollector, mockGPUCollector)
+	model, err := InitialModel(
+		Plugin{Name: "cpu", NewSource: func() Source { return NewSource[domain.CPUMemoryMetrics](mockCPUMemoryCollector) }},
+		Plugin{Name: "nvidia", NewSource: func() Source { return NewSource[domain.GPUMetrics](mockGPUCollector) }},
+	)
 
 	assert.NoError(t, err)
-	assert.NotNil(t, model.cpuMemoryCollector)
-	assert.NotNil(t, model.gpuCollector)
+	assert.NotNil(t, model.sources["cpu"])
+	assert.NotNil(t, model.sources["nvidia"])
 
 	mockCPUMemoryCollector.AssertCalled(t, "Start")
 	mockGPUCollector.AssertCalled(t, "Start")
@@ -110,3 +124,499 @@ func TestInitialModelError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "no valid collectors provided", err.Error())
 }
+
+func TestInitialModelPluginPanel(t *testing.T) {
+	mockPSICollector := new(MockMetricsCollector[domain.PSIMetrics])
+	mockPSICollector.On("Start").Return()
+
+	model, err := InitialModel(Plugin{
+		Name:      "psi",
+		NewSource: func() Source { return NewSource[domain.PSIMetrics](mockPSICollector) },
+		NewPanel:  func() Panel { return NewPSIPanel() },
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, model.panels, 1)
+
+	model.updateMetrics(domain.PSIMetrics{})
+	assert.Contains(t, model.renderContent(), "PSI not available")
+}
+
+func TestRegistryResolve(t *testing.T) {
+	newSource := func() Source { return NewSource[domain.GPUMetrics](new(MockMetricsCollector[domain.GPUMetrics])) }
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register(Plugin{Name: "cpu", DefaultEnabled: true, NewSource: newSource}))
+	assert.NoError(t, registry.Register(Plugin{Name: "gpu", DefaultEnabled: true, NewSource: newSource, Available: func() bool { return false }}))
+	assert.NoError(t, registry.Register(Plugin{Name: "extra", NewSource: newSource}))
+	assert.Error(t, registry.Register(Plugin{Name: "cpu", NewSource: newSource}))
+
+	names := func(plugins []Plugin) []string {
+		var out []string
+		for _, p := range plugins {
+			out = append(out, p.Name)
+		}
+		return out
+	}
+
+	plugins, err := registry.Resolve(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cpu"}, names(plugins))
+
+	plugins, err = registry.Resolve(map[string]bool{"cpu": false, "extra": true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"extra"}, names(plugins))
+
+	_, err = registry.Resolve(map[string]bool{"gpu": true})
+	assert.ErrorContains(t, err, "[gpu]")
+
+	_, err = registry.Resolve(map[string]bool{"nope": true})
+	assert.Error(t, err)
+}
+
+func TestModelTabs(t *testing.T) {
+	mockCPUMemoryCollector := new(MockMetricsCollector[domain.CPUMemoryMetrics])
+	mockCPUMemoryCollector.On("Start").Return()
+
+	model, err := InitialModel(Plugin{Name: "cpu", NewSource: func() Source { return NewSource[domain.CPUMemoryMetrics](mockCPUMemoryCollector) }})
+	assert.NoError(t, err)
+
+	updated, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("5")})
+	model = updated.(Model)
+
+	assert.Equal(t, 4, model.activeTab)
+	assert.Contains(t, model.View(), "GPU MEM")
+	assert.NotContains(t, model.View(), "CPU Usage:")
+	assert.Equal(t, 40, lipgloss.Height(model.View()))
+}
+
+func TestModelZoom(t *testing.T) {
+	mockCPUMemoryCollector := new(MockMetricsCollector[domain.CPUMemoryMetrics])
+	mockCPUMemoryCollector.On("Start").Return()
+
+	model, err := InitialModel(Plugin{Name: "cpu", NewSource: func() Source { return NewSource[domain.CPUMemoryMetrics](mockCPUMemoryCollector) }})
+	assert.NoError(t, err)
+
+	updated, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	for _, key := range []string{"]", "]", "]", "z"} {
+		updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+	model = updated.(Model)
+
+	assert.Equal(t, PanelMemory, model.focus)
+	assert.True(t, model.memoryUsageGraph.Focused())
+	assert.Contains(t, model.View(), "now")
+	assert.Contains(t, model.View(), "-10s")
+	assert.NotContains(t, model.View(), "CPU Usage:")
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Contains(t, updated.View(), "CPU Usage:")
+	// Only the focused panel is marked, on each of its lines
+	content, regions, _ := updated.(Model).renderRegions()
+	for _, region := range regions {
+		if region.name == PanelMemory {
+			assert.Equal(t, region.height, strings.Count(content, "▎"))
+		}
+	}
+}
+
+func TestTimeAxisView(t *testing.T) {
+	assert.Equal(t, "        -20s      -10s       now", timeAxisView(32, 0, time.Second))
+	assert.Equal(t, "        -10m       -5m       now", timeAxisView(32, 4, 30*time.Second))
+	assert.Equal(t, "2h30m", formatAge(150*time.Minute))
+	assert.Equal(t, "1h", formatAge(time.Hour))
+}
+
+func TestModelPause(t *testing.T) {
+	mockCPUMemoryCollector := new(MockMetricsCollector[domain.CPUMemoryMetrics])
+	mockCPUMemoryCollector.On("Start").Return()
+	mockCPUMemoryCollector.On("Metrics").Return(make(chan domain.CPUMemoryMetrics))
+
+	model, err := InitialModel(Plugin{Name: "cpu", NewSource: func() Source { return NewSource[domain.CPUMemoryMetrics](mockCPUMemoryCollector) }})
+	assert.NoError(t, err)
+
+	var updated tea.Model = model
+	updated, _ = updated.Update(metricsMsg{source: "cpu", value: domain.CPUMemoryMetrics{CPUUsageTotal: 10}})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	updated, _ = updated.Update(metricsMsg{source: "cpu", value: domain.CPUMemoryMetrics{CPUUsageTotal: 20}})
+
+	paused := updated.(Model)
+	assert.True(t, paused.paused)
+	assert.Equal(t, 10.0, paused.cpuUsageTotal)
+	assert.Contains(t, paused.View(), "CPU Usage: 10.00%")
+	assert.Contains(t, paused.View(), "PAUSED")
+
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	assert.Equal(t, 20.0, updated.(Model).cpuUsageTotal)
+}
+
+func TestModelMouse(t *testing.T) {
+	mockCPUMemoryCollector := new(MockMetricsCollector[domain.CPUMemoryMetrics])
+	mockCPUMemoryCollector.On("Start").Return()
+	mockCPUMemoryCollector.On("Metrics").Return(make(chan domain.CPUMemoryMetrics))
+
+	model, err := InitialModel(Plugin{Name: "cpu", NewSource: func() Source { return NewSource[domain.CPUMemoryMetrics](mockCPUMemoryCollector) }})
+	assert.NoError(t, err)
+
+	var updated tea.Model = model
+	updated, _ = updated.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	updated, _ = updated.Update(metricsMsg{source: "cpu", value: domain.CPUMemoryMetrics{
+		CPUUsageTotal: 30,
+		Processes: []domain.CPUProcessInfo{
+			{Pid: 42, CPUPercent: 50, Command: "busy"},
+			{Pid: 7, CPUPercent: 10, Command: "idle"},
+		},
+	}})
+
+	// Second row of the CPU table: tab bar, hidden border, title, header and border above
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("5")})
+	updated, _ = updated.Update(tea.MouseMsg{X: 10, Y: 6, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	model = updated.(Model)
+	assert.Equal(t, PanelProcesses, model.focus)
+	assert.Equal(t, uint32(7), model.selectedPID)
+	assert.Equal(t, 1, model.processMonitor.cpuTable.Cursor())
+
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+	model = updated.(Model)
+	var graph panelRegion
+	for _, region := range model.regions {
+		if region.name == PanelCPUGraph {
+			graph = region
+		}
+	}
+	// The newest sample is drawn in the graph's rightmost column
+	x := graph.x + model.cpuGPUUsageGraph.slc.Width() - 1
+	updated, _ = updated.Update(tea.MouseMsg{X: x, Y: tabBarHeight + graph.y + 1, Action: tea.MouseActionMotion})
+	assert.Contains(t, updated.(Model).statusBarView(), "CPU 30.0%")
+}
+
+func TestModelHelp(t *testing.T) {
+	mockCPUMemoryCollector := new(MockMetricsCollector[domain.CPUMemoryMetrics])
+	mockCPUMemoryCollector.On("Start").Return()
+
+	model, err := InitialModel(Plugin{Name: "cpu", NewSource: func() Source { return NewSource[domain.CPUMemoryMetrics](mockCPUMemoryCollector) }})
+	assert.NoError(t, err)
+
+	var updated tea.Model = model
+	updated, _ = updated.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	assert.Contains(t, updated.View(), "step back 10s")
+	assert.Equal(t, 40, lipgloss.Height(updated.View()))
+
+	updated, cmd := updated.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	assert.Nil(t, cmd)
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, updated.(Model).showHelp)
+
+	// End scrolls instead of quitting
+	_, cmd = updated.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	assert.Nil(t, cmd)
+}
+
+func TestKeyMapRemap(t *testing.T) {
+	keys := DefaultKeyMap()
+	assert.Empty(t, keys.Conflicts())
+
+	assert.NoError(t, keys.Remap(map[string][]string{"quit": {"x"}, "zoom": {"x"}}))
+	assert.Equal(t, []string{`key "x" is bound to quit, zoom`}, keys.Conflicts())
+	assert.Equal(t, "x", keys.Quit.Help().Key)
+
+	assert.Error(t, keys.Remap(map[string][]string{"explode": {"e"}}))
+}
+
+func TestModelCompact(t *testing.T) {
+	mockCPUMemoryCollector := new(MockMetricsCollector[domain.CPUMemoryMetrics])
+	mockCPUMemoryCollector.On("Start").Return()
+	mockCPUMemoryCollector.On("Metrics").Return(make(chan domain.CPUMemoryMetrics))
+
+	model, err := InitialModel(Plugin{Name: "cpu", NewSource: func() Source { return NewSource[domain.CPUMemoryMetrics](mockCPUMemoryCollector) }})
+	assert.NoError(t, err)
+
+	var updated tea.Model = model
+	updated, _ = updated.Update(tea.WindowSizeMsg{Width: 40, Height: 10})
+	updated, _ = updated.Update(metricsMsg{source: "cpu", value: domain.CPUMemoryMetrics{
+		CPUUsageTotal: 30,
+		Processes: []domain.CPUProcessInfo{
+			{Pid: 1, CPUPercent: 1, Command: "init"},
+			{Pid: 2, CPUPercent: 4, Command: "second"},
+			{Pid: 3, CPUPercent: 3, Command: "third"},
+			{Pid: 4, CPUPercent: 50, Command: "busy"},
+		},
+	}})
+
+	view := updated.View()
+	assert.Equal(t, 10, lipgloss.Height(view))
+	assert.Contains(t, view, "30.0%")
+	assert.Contains(t, view, "busy")
+	assert.NotContains(t, view, "init")
+	assert.NotContains(t, view, "q quit")
+
+	// The overlays have no room here, so their keys are ignored
+	for _, key := range []string{"?", "s"} {
+		updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+	assert.False(t, updated.(Model).showHelp)
+	assert.False(t, updated.(Model).showStats)
+	assert.Equal(t, view, updated.View())
+
+	updated, _ = updated.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	assert.Contains(t, updated.View(), "q quit")
+}
+
+func TestOnelineSummary(t *testing.T) {
+	var summary onelineSummary
+	assert.Empty(t, summary.String())
+
+	summary.update(domain.CPUMemoryMetrics{CPUUsageTotal: 23.4, MemoryUsage: 41})
+	summary.update(domain.GPUMetrics{Devices: []domain.GPUDeviceMetrics{
+		{Index: 0, Usage: 10, MemoryUsage: 5},
+		{Index: 1, Usage: 90, MemoryUsage: 60},
+	}})
+	assert.Equal(t, "CPU 23% MEM 41% GPU0 10% VRAM0 5% GPU1 90% VRAM1 60%", summary.String())
+}
+
+func TestClusterModel(t *testing.T) {
+	newPlugin := func() Plugin {
+		collector := new(MockMetricsCollector[domain.CPUMemoryMetrics])
+		collector.On("Start").Return()
+		collector.On("Stop").Return()
+		collector.On("Metrics").Return(make(chan domain.CPUMemoryMetrics))
+		return Plugin{Name: "cpu", NewSource: func() Source { return NewSource[domain.CPUMemoryMetrics](collector) }}
+	}
+	hosts := []ClusterHost{
+		{Name: "gpu-box", Summary: []Plugin{newPlugin()}, Plugins: []Plugin{newPlugin()}},
+		{Name: "db-box", Summary: []Plugin{newPlugin()}, Plugins: []Plugin{newPlugin()}},
+	}
+	cluster, err := NewClusterModel(hosts, nil)
+	assert.NoError(t, err)
+
+	var updated tea.Model = cluster
+	updated, _ = updated.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+	updated, cmd := updated.Update(clusterMetricsMsg{host: 0, source: "cpu", value: domain.CPUMemoryMetrics{
+		CPUUsageTotal: 23,
+		Processes:     []domain.CPUProcessInfo{{Pid: 9, CPUPercent: 80, Command: "train"}},
+	}})
+	assert.NotNil(t, cmd)
+	updated, _ = updated.Update(clusterMetricsMsg{host: 0, source: "nvidia", value: domain.GPUMetrics{Devices: []domain.GPUDeviceMetrics{{Index: 0, Usage: 40}, {Index: 1, Usage: 60}}}})
+
+	view := updated.View()
+	assert.Contains(t, view, "Cluster: 2 hosts, 1 up")
+	assert.Contains(t, view, "23.0%")
+	assert.Contains(t, view, "train 80.0%")
+	assert.Contains(t, view, "GPU1")
+	assert.Regexp(t, `db-box\s+waiting`, view)
+
+	// A host that stops sending is shown as offline
+	updated.(ClusterModel).hosts[0].lastSeen = time.Now().Add(-time.Minute)
+	assert.Regexp(t, `gpu-box\s+offline`, updated.View())
+
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyDown})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Contains(t, updated.View(), "db-box (backspace: back to cluster)")
+
+	// Messages of a closed host are dropped
+	generation := updated.(ClusterModel).generation
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	assert.Nil(t, updated.(ClusterModel).host)
+	updated, cmd = updated.Update(hostMsg{generation: generation, msg: metricsMsg{source: "cpu"}})
+	assert.Nil(t, cmd)
+	assert.Contains(t, updated.View(), "Cluster: 2 hosts")
+}
+
+func TestHistoryWriteCSV(t *testing.T) {
+	history := newMetricsHistory(time.Minute)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	history.add(start, "cpu", domain.CPUMemoryMetrics{CPUUsageTotal: 1}) // before the first GPU sample
+	history.add(start.Add(100*time.Millisecond), "nvidia", domain.GPUMetrics{GPUUsage: 50})
+	history.add(start.Add(time.Second), "cpu", domain.CPUMemoryMetrics{CPUUsageTotal: 2, Processes: []domain.CPUProcessInfo{{Pid: 3, Command: "job"}}})
+	history.add(start.Add(2*time.Second), "cpu", domain.CPUMemoryMetrics{CPUUsageTotal: 3})
+
+	dir := t.TempDir()
+	rows, err := history.writeCSV(filepath.Join(dir, "system.csv"), filepath.Join(dir, "processes.csv"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, rows)
+
+	system, _ := os.ReadFile(filepath.Join(dir, "system.csv"))
+	assert.Equal(t, "timestamp,cpu_total,memory,gpu0_util,gpu0_mem\n"+
+		"2024-05-01T12:00:01.000Z,2.00,0.00,50.00,0.00\n"+
+		"2024-05-01T12:00:02.000Z,3.00,0.00,50.00,0.00\n", string(system))
+	processes, _ := os.ReadFile(filepath.Join(dir, "processes.csv"))
+	assert.Contains(t, string(processes), "2024-05-01T12:00:01.000Z,3,,job,0.00,0.00,\n")
+
+	model := Model{keys: DefaultKeyMap(), history: newMetricsHistory(time.Minute), viewport: viewport.New(80, 10)}
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	assert.Contains(t, updated.View(), "no history to export")
+}
+
+func TestHistoryWriteCSVProcesses(t *testing.T) {
+	history := newMetricsHistory(time.Minute)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	buffer := []domain.CPUProcessInfo{{Pid: 3, Command: "build"}}
+	history.add(start, "cpu", domain.CPUMemoryMetrics{Processes: buffer})
+	buffer[0] = domain.CPUProcessInfo{Pid: 4, Command: "test"} // a source reusing its buffer
+	history.add(start.Add(time.Second), "cpu", domain.CPUMemoryMetrics{Processes: buffer})
+
+	dir := t.TempDir()
+	rows, err := history.writeCSV(filepath.Join(dir, "system.csv"), filepath.Join(dir, "processes.csv"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, rows)
+
+	processes, _ := os.ReadFile(filepath.Join(dir, "processes.csv"))
+	lines := strings.Split(strings.TrimSpace(string(processes)), "\n")
+	assert.Equal(t, []string{
+		"2024-05-01T12:00:00.000Z,3,,build,0.00,0.00,",
+		"2024-05-01T12:00:01.000Z,4,,test,0.00,0.00,",
+	}, lines[1:])
+}
+
+func TestModelReplayKeepsProcesses(t *testing.T) {
+	mockCPUMemoryCollector := new(MockMetricsCollector[domain.CPUMemoryMetrics])
+	mockCPUMemoryCollector.On("Start").Return()
+	mockCPUMemoryCollector.On("Metrics").Return(make(chan domain.CPUMemoryMetrics))
+
+	model, err := InitialModel(Plugin{Name: "cpu", NewSource: func() Source { return NewSource[domain.CPUMemoryMetrics](mockCPUMemoryCollector) }})
+	assert.NoError(t, err)
+
+	// A source that reuses its buffer overwrites the list it sent before
+	buffer := []domain.CPUProcessInfo{{Pid: 7, CPUPercent: 50, Command: "first-job"}}
+	var updated tea.Model = model
+	updated, _ = updated.Update(metricsMsg{source: "cpu", value: domain.CPUMemoryMetrics{Processes: buffer}})
+	buffer[0].Command = "second-job"
+	updated, _ = updated.Update(metricsMsg{source: "cpu", value: domain.CPUMemoryMetrics{Processes: buffer}})
+
+	history := updated.(Model).history
+	replay := updated.(Model).replayAt(history.oldest())
+	assert.Contains(t, replay.renderContent(), "first-job")
+	assert.NotContains(t, replay.renderContent(), "second-job")
+}
+
+func TestModelShowArchive(t *testing.T) {
+	mockCPUMemoryCollector := new(MockMetricsCollector[domain.CPUMemoryMetrics])
+	mockCPUMemoryCollector.On("Start").Return()
+
+	model, err := InitialModel(Plugin{Name: "cpu", NewSource: func() Source { return NewSource[domain.CPUMemoryMetrics](mockCPUMemoryCollector) }})
+	assert.NoError(t, err)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	model.ShowArchive([]store.Sample{
+		{Time: start, CPU: 10},
+		{Time: start.Add(10 * time.Second), CPU: 20, GPUs: []store.GPU{{Usage: 60}}},
+		{Time: start.Add(20 * time.Second), CPU: 30, GPUs: []store.GPU{{Usage: 70}}},
+	}, 10*time.Second)
+
+	var updated tea.Model = model
+	updated, _ = updated.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	assert.Contains(t, updated.View(), "STORED 2024-05-01 12:00:20 (10s samples)")
+	assert.Contains(t, updated.View(), "CPU Usage: 30.00%")
+
+	// A step moves one stored sample back
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	assert.Contains(t, updated.View(), "CPU Usage: 20.00%")
+	assert.Contains(t, updated.View(), "GPU Usage: 60.00%")
+
+	// Resuming shows the live metrics
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	assert.False(t, updated.(Model).paused)
+	assert.Nil(t, updated.(Model).archive)
+	assert.Contains(t, updated.View(), "CPU Usage: 0.00%")
+}
+
+func TestModelStats(t *testing.T) {
+	mockCPUMemoryCollector := new(MockMetricsCollector[domain.CPUMemoryMetrics])
+	mockCPUMemoryCollector.On("Start").Return()
+	mockCPUMemoryCollector.On("Metrics").Return(make(chan domain.CPUMemoryMetrics))
+
+	model, err := InitialModel(Plugin{Name: "cpu", NewSource: func() Source { return NewSource[domain.CPUMemoryMetrics](mockCPUMemoryCollector) }})
+	assert.NoError(t, err)
+
+	var updated tea.Model = model
+	updated, _ = updated.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	for _, usage := range []float64{10, 30} {
+		updated, _ = updated.Update(metricsMsg{source: "cpu", value: domain.CPUMemoryMetrics{
+			CPUUsageTotal:   usage,
+			CPUUsagePerCore: []float64{usage},
+			MemoryUsage:     50,
+			Processes:       []domain.CPUProcessInfo{{Pid: 42, User: "alice", Command: "make", CPUPercent: usage}},
+		}})
+	}
+
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	view := updated.View()
+	assert.Contains(t, view, "Statistics since start")
+	assert.Regexp(t, `cpu +2 +10\.0% +20\.0% +30\.0% +30\.0%`, view)
+	assert.Regexp(t, `42 +alice +make +20\.0%`, view)
+	assert.Equal(t, 40, lipgloss.Height(view))
+
+	// Samples keep updating the overlay
+	updated, _ = updated.Update(metricsMsg{source: "cpu", value: domain.CPUMemoryMetrics{CPUUsageTotal: 50}})
+	assert.Regexp(t, `cpu +3 +10\.0% +30\.0%`, updated.View())
+
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, updated.(Model).showStats)
+	assert.Contains(t, updated.View(), "CPU Usage: 50.00%")
+	assert.Equal(t, 3, updated.(Model).RunStats().Get("cpu").Count())
+}
+
+func TestModelProcessTree(t *testing.T) {
+	mockCPUMemoryCollector := new(MockMetricsCollector[domain.CPUMemoryMetrics])
+	mockCPUMemoryCollector.On("Start").Return()
+	mockCPUMemoryCollector.On("Metrics").Return(make(chan domain.CPUMemoryMetrics))
+
+	model, err := InitialModel(Plugin{Name: "cpu", NewSource: func() Source { return NewSource[domain.CPUMemoryMetrics](mockCPUMemoryCollector) }})
+	assert.NoError(t, err)
+	model.SetProcessTree(domain.NewProcessTree(10), "train.py")
+	var observed domain.CPUMemoryMetrics
+	model.SetMetricsObserver(func(value any) { observed = value.(domain.CPUMemoryMetrics) })
+
+	var updated tea.Model = model
+	updated, _ = updated.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	updated, _ = updated.Update(metricsMsg{source: "cpu", value: domain.CPUMemoryMetrics{
+		CPUUsageTotal:   90,
+		CPUUsagePerCore: []float64{90},
+		Processes: []domain.CPUProcessInfo{
+			{Pid: 10, PPid: 1, Command: "python", CPUPercent: 30},
+			{Pid: 11, PPid: 10, Command: "worker", CPUPercent: 20},
+			{Pid: 20, PPid: 1, Command: "compiler", CPUPercent: 40},
+		},
+	}})
+
+	view := updated.View()
+	assert.Contains(t, view, "job: train.py (2 processes)")
+	assert.Contains(t, view, "CPU Usage: 50.00%")
+	assert.Contains(t, view, "worker")
+	assert.NotContains(t, view, "compiler")
+	assert.Equal(t, 90.0, observed.CPUUsageTotal)
+}
+
+func TestModelWatchList(t *testing.T) {
+	mockCPUMemoryCollector := new(MockMetricsCollector[domain.CPUMemoryMetrics])
+	mockCPUMemoryCollector.On("Start").Return()
+	mockCPUMemoryCollector.On("Metrics").Return(make(chan domain.CPUMemoryMetrics))
+
+	model, err := InitialModel(Plugin{Name: "cpu", NewSource: func() Source { return NewSource[domain.CPUMemoryMetrics](mockCPUMemoryCollector) }})
+	assert.NoError(t, err)
+	model.SetWatchList(domain.NewWatchList([]uint32{42, 99}, regexp.MustCompile("^nginx")))
+
+	var updated tea.Model = model
+	updated, _ = updated.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	updated, _ = updated.Update(metricsMsg{source: "cpu", value: domain.CPUMemoryMetrics{Processes: []domain.CPUProcessInfo{
+		{Pid: 42, User: "alice", Command: "postgres", CPUPercent: 12.5},
+		{Pid: 50, User: "www", Command: "nginx", CPUPercent: 3},
+	}}})
+	view := updated.View()
+	assert.Contains(t, view, "Watched")
+	assert.Regexp(t, `running +42 +alice +12\.5%.*postgres`, view)
+	assert.Regexp(t, `not found +99`, view)
+	assert.Regexp(t, `running +50 +www +3\.0%.*nginx`, view)
+
+	// An exited process keeps its row
+	updated, _ = updated.Update(metricsMsg{source: "cpu", value: domain.CPUMemoryMetrics{Processes: []domain.CPUProcessInfo{
+		{Pid: 50, User: "www", Command: "nginx", CPUPercent: 4},
+	}}})
+	assert.Regexp(t, `exited +42 +alice +0\.0%.*postgres`, updated.View())
+
+	// The processes screen shows the watched processes too, also when paused
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("5")})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	assert.Regexp(t, `exited +42`, updated.View())
+	assert.Regexp(t, `running +50 +www +4\.0%`, updated.View())
+}
@@ -0,0 +1,152 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+// mouseWheelLines is how far one wheel step scrolls the viewport
+const mouseWheelLines = 3
+
+// panelRegion is where a panel was drawn within the viewport content
+type panelRegion struct {
+	name                string
+	x, y, width, height int
+}
+
+func (r panelRegion) contains(x, y int) bool {
+	return x >= r.x && x < r.x+r.width && y >= r.y && y < r.y+r.height
+}
+
+// sampleLocator is implemented by streaming charts that can tell which
+// sample is drawn under a point
+type sampleLocator interface {
+	SampleAge(x, y int) (int, bool)
+}
+
+// handleMouse scrolls on the wheel, focuses the clicked panel and selects
+// the clicked process, and keeps the tooltip in step with the pointer
+func (m *Model) handleMouse(msg tea.MouseMsg) {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.viewport.LineUp(mouseWheelLines)
+		return
+	case tea.MouseButtonWheelDown:
+		m.viewport.LineDown(mouseWheelLines)
+		return
+	}
+
+	region, x, y, ok := m.regionAt(msg.X, msg.Y)
+	if !ok {
+		m.tooltip = ""
+		return
+	}
+
+	switch msg.Action {
+	case tea.MouseActionPress:
+		if msg.Button != tea.MouseButtonLeft {
+			return
+		}
+		if region.name != m.focus {
+			m.setFocus(region.name)
+		}
+		if pm, ok := m.panel(region.name).(*ProcessMonitor); ok {
+			if pid, ok := pm.ProcessAt(x, y); ok {
+				if pid == m.selectedPID {
+					pid = 0
+				}
+				m.selectProcess(pid)
+			}
+		}
+		m.refreshContent()
+	case tea.MouseActionMotion:
+		m.tooltip = m.hoverText(region.name, x, y)
+	}
+}
+
+// regionAt finds the panel under a screen position and returns the
+// position relative to that panel
+func (m Model) regionAt(screenX, screenY int) (panelRegion, int, int, bool) {
+	y := screenY - m.bodyTop()
+	if y < 0 || y >= m.viewport.Height {
+		return panelRegion{}, 0, 0, false
+	}
+	y += m.viewport.YOffset
+	for _, region := range m.regions {
+		if region.contains(screenX, y) {
+			return region, screenX - region.x, y - region.y, true
+		}
+	}
+	return panelRegion{}, 0, 0, false
+}
+
+// selectProcess highlights a process in every process table; 0 clears the
+// selection
+func (m *Model) selectProcess(pid uint32) {
+	m.selectedPID = pid
+	if m.processMonitor != nil {
+		m.processMonitor.SelectProcess(pid)
+	}
+	for _, pm := range m.processTables {
+		pm.SelectProcess(pid)
+	}
+}
+
+// hoverText describes the graph sample under the pointer, looked up in the
+// history by its age. Nothing is shown over other panels.
+func (m Model) hoverText(name string, x, y int) string {
+	locator, ok := m.panel(name).(sampleLocator)
+	history, _ := m.shownHistory()
+	if !ok || history == nil {
+		return ""
+	}
+	age, ok := locator.SampleAge(x, y)
+	if !ok {
+		return ""
+	}
+
+	shown := history.newest()
+	if m.paused {
+		shown = m.cursor
+	}
+	cpuEntry, hasCPU := history.latest(shown, age, func(v any) bool {
+		_, ok := v.(domain.CPUMemoryMetrics)
+		return ok
+	})
+	gpuEntry, hasGPU := history.latest(shown, age, func(v any) bool {
+		_, ok := v.(domain.GPUMetrics)
+		return ok
+	})
+
+	var at time.Time
+	var parts []string
+	if hasCPU {
+		at = cpuEntry.at
+		metrics := cpuEntry.value.(domain.CPUMemoryMetrics)
+		if name == PanelMemory {
+			parts = append(parts, fmt.Sprintf("Memory %.1f%%", metrics.MemoryUsage))
+		} else {
+			parts = append(parts, fmt.Sprintf("CPU %.1f%%", metrics.CPUUsageTotal))
+		}
+	}
+	if hasGPU {
+		if at.IsZero() {
+			at = gpuEntry.at
+		}
+		metrics := gpuEntry.value.(domain.GPUMetrics)
+		if name == PanelMemory {
+			parts = append(parts, fmt.Sprintf("GPU memory %.1f%%", metrics.GPUMemoryUsage))
+		} else {
+			parts = append(parts, fmt.Sprintf("GPU %.1f%%", metrics.GPUUsage))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s  %s (%s ago)", at.Format("15:04:05"), strings.Join(parts, "  "),
+		shown.Sub(at).Round(time.Second))
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+// onelineInterval matches the collectors' sampling interval
+const onelineInterval = time.Second
+
+// RunOneline starts the plugins' collectors and writes a single summary line
+// to w every interval, for status bars such as tmux's status-right. It
+// returns when stop is closed.
+func RunOneline(w io.Writer, stop <-chan struct{}, plugins ...Plugin) error {
+	if len(plugins) == 0 {
+		return fmt.Errorf("no valid collectors provided")
+	}
+
+	values := make(chan any)
+	for _, plugin := range plugins {
+		source := plugin.NewSource()
+		source.Start()
+		defer source.Stop()
+		go forward(source.listen(plugin.Name), values, stop)
+	}
+
+	var summary onelineSummary
+	ticker := time.NewTicker(onelineInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case value := <-values:
+			summary.update(value)
+		case <-ticker.C:
+			if line := summary.String(); line != "" {
+				if _, err := fmt.Fprintln(w, line); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// forward sends every value a source produces until the source closes or
+// stop is closed
+func forward(listen tea.Cmd, values chan<- any, stop <-chan struct{}) {
+	for {
+		msg, ok := listen().(metricsMsg)
+		if !ok {
+			return
+		}
+		select {
+		case values <- msg.value:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// onelineSummary keeps the latest CPU and GPU metrics for the status line
+type onelineSummary struct {
+	cpu *domain.CPUMemoryMetrics
+	gpu *domain.GPUMetrics
+}
+
+func (s *onelineSummary) update(value any) {
+	switch value := value.(type) {
+	case domain.CPUMemoryMetrics:
+		s.cpu = &value
+	case domain.GPUMetrics:
+		s.gpu = &value
+	}
+}
+
+// String renders for example "CPU 23% MEM 41% GPU 10% VRAM 5%"; empty until
+// the first sample arrives
+func (s onelineSummary) String() string {
+	var parts []string
+	if s.cpu != nil {
+		parts = append(parts, fmt.Sprintf("CPU %.0f%% MEM %.0f%%", s.cpu.CPUUsageTotal, s.cpu.MemoryUsage))
+	}
+	if s.gpu != nil {
+		devices := s.gpu.Devices
+		if len(devices) == 0 {
+			devices = []domain.GPUDeviceMetrics{{Usage: s.gpu.GPUUsage, MemoryUsage: s.gpu.GPUMemoryUsage}}
+		}
+		for _, device := range devices {
+			suffix := strings.TrimPrefix(gpuLabel(device.Index, len(devices)), "GPU")
+			parts = append(parts, fmt.Sprintf("GPU%s %.0f%% VRAM%s %.0f%%", suffix, device.Usage, suffix, device.MemoryUsage))
+		}
+	}
+	return strings.Join(parts, " ")
+}
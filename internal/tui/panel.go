@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/NimbleMarkets/ntcharts/linechart/streamlinechart"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Panel is a view the layout can place, size, focus and zoom. The built-in
+// views and plugin panels implement it alike. Every metrics message received
+// by the model is passed to Update; panels ignore types they do not
+// understand.
+type Panel interface {
+	Update(msg interface{})
+	View() string
+	// Resize sets the panel's cell size; a zero height asks for the
+	// panel's natural height
+	Resize(width, height int)
+	Focus()
+	Blur()
+	Focused() bool
+}
+
+// Zoomable panels show extra detail, such as axis labels, while they fill
+// the screen
+type Zoomable interface {
+	SetZoomed(zoomed bool)
+}
+
+// focusState implements the focus methods of Panel for embedding
+type focusState struct {
+	focused bool
+}
+
+func (f *focusState) Focus()        { f.focused = true }
+func (f *focusState) Blur()         { f.focused = false }
+func (f *focusState) Focused() bool { return f.focused }
+
+// focusGutterWidth is the column left of every panel that marks the focused one
+const focusGutterWidth = 1
+
+var focusGutterStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("12")) // bright blue
+
+// focusGutterView draws the gutter beside a panel: a bar when it has focus
+func focusGutterView(panel Panel, height int) string {
+	mark := " "
+	if panel != nil && panel.Focused() {
+		mark = focusGutterStyle.Render("▎")
+	}
+	return strings.TrimSuffix(strings.Repeat(mark+"\n", max(height, 1)), "\n")
+}
+
+// timeAxisView labels the columns of a right-to-left streaming chart with
+// the age of their samples, each column step older than the next
+func timeAxisView(width, originX int, step time.Duration) string {
+	line := []rune(strings.Repeat(" ", max(width, 0)))
+	put := func(col int, label string) bool {
+		start := col - len(label) + 1
+		if start <= originX || col >= len(line) {
+			return false
+		}
+		copy(line[start:], []rune(label))
+		return true
+	}
+
+	put(width-1, "now")
+	for columns := 10; ; columns += 10 {
+		if !put(width-1-columns, "-"+formatAge(time.Duration(columns)*step)) {
+			break
+		}
+	}
+	return labelStyle.Render(string(line))
+}
+
+// formatAge renders an axis age such as 10s, 2m30s or 1h
+func formatAge(age time.Duration) string {
+	label := age.Round(time.Second).String()
+	if strings.HasSuffix(label, "m0s") {
+		label = strings.TrimSuffix(label, "0s")
+	}
+	if strings.HasSuffix(label, "h0m") {
+		label = strings.TrimSuffix(label, "0m")
+	}
+	return label
+}
+
+// streamAge maps a point on a streaming chart to the age of the sample drawn
+// in that column, newest at the right edge; false outside the plot area
+func streamAge(slc *streamlinechart.Model, x, y int) (int, bool) {
+	if x <= slc.Origin().X || x >= slc.Width() || y < 0 || y >= slc.Height() {
+		return 0, false
+	}
+	return slc.Width() - 1 - x, true
+}
@@ -6,21 +6,27 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/bubbles/table"
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jonsampson/mim/internal/domain"
 )
 
 type ProcessMonitor struct {
-	cpuProcesses    []domain.CPUProcessInfo
-	gpuProcesses    []domain.GPUProcessInfo
+	focusState
+	ranking         *processRanking
+	ownsRanking     bool // sorts the ranking itself rather than sharing the model's
 	cpuTable        table.Model
 	memTable        table.Model
+	ioReadTable     table.Model
+	ioWriteTable    table.Model
 	gpuTable        table.Model
 	gpuMemTable     table.Model
-	symbolAllocator *SymbolAllocator
 	symbolColors    []lipgloss.Style
 	width           int
+	rowCount        int
+	tables          []processTable
+	rowPIDs         map[processTable][]uint32 // process shown on each row
+	selectedPID     uint32                    // highlighted process, 0 for none
+	ioHidden        int                       // processes with unreadable I/O left off the IO tables
 	borderStyle     lipgloss.Style
 	// Pre-allocated buffers for string formatting
 	rowBuffer       []table.Row
@@ -33,45 +39,84 @@ const (
 	userWidth      = 12
 	metricWidth    = 12
 	minCommandWidth = 20 // Minimum viable command column width
+
+	percentTitle = "         %"
+	rateTitle    = "       B/s"
+
+	defaultProcessRows = 5
+	tableChromeHeight  = 3 // title, header and header border
+)
+
+// processTable identifies one of the monitor's tables
+type processTable int
+
+const (
+	processCPU processTable = iota
+	processMem
+	processIORead
+	processIOWrite
+	processGPU
+	processGPUMem
 )
 
+var allProcessTables = []processTable{processCPU, processMem, processIORead, processIOWrite, processGPU, processGPUMem}
+
 func NewProcessMonitor(width int) *ProcessMonitor {
+	pm := newProcessMonitor(width, newProcessRanking(), allProcessTables...)
+	pm.ownsRanking = true
+	return pm
+}
+
+// newProcessMonitor creates a monitor that shows only the given tables from
+// a ranking the caller keeps up to date
+func newProcessMonitor(width int, ranking *processRanking, tables ...processTable) *ProcessMonitor {
 	pm := &ProcessMonitor{
-		width:           width,
-		symbolAllocator: NewSymbolAllocator([]rune{'▣', '▤', '▥', '▦', '▧', '▨', '▩', '▪', '▫', '▬', '◆', '◇', '○', '●', '◉', '◍', '◎', '◌', '◔', '◕'}),
-		borderStyle:     lipgloss.NewStyle().Padding(0).Margin(0),
-		rowBuffer:       make([]table.Row, 0, 5), // Pre-allocate for 5 rows
+		ranking:     ranking,
+		width:       width,
+		rowCount:    defaultProcessRows,
+		tables:      tables,
+		rowPIDs:     make(map[processTable][]uint32),
+		borderStyle: lipgloss.NewStyle().Padding(0).Margin(0),
+		rowBuffer:   make([]table.Row, 0, 5), // Pre-allocate for 5 rows
 	}
 
-	pm.symbolColors = createSymbolColors(len(pm.symbolAllocator.symbols))
-	pm.cpuTable = pm.createTable()
-	pm.memTable = pm.createTable()
-	pm.gpuTable = pm.createTable()
-	pm.gpuMemTable = pm.createTable()
+	pm.symbolColors = createSymbolColors(len(ranking.symbols.symbols))
+	pm.cpuTable = pm.createTable(percentTitle)
+	pm.memTable = pm.createTable(percentTitle)
+	pm.ioReadTable = pm.createTable(rateTitle)
+	pm.ioWriteTable = pm.createTable(rateTitle)
+	pm.gpuTable = pm.createTable(percentTitle)
+	pm.gpuMemTable = pm.createTable(percentTitle)
 
 	return pm
 }
 
-func (pm *ProcessMonitor) createTable() table.Model {
-	return pm.createTableWithWidth(pm.width/2 - 4)
+func (pm *ProcessMonitor) createTable(metricTitle string) table.Model {
+	return pm.createTableWithWidth(pm.width/2-4, metricTitle)
 }
 
-func (pm *ProcessMonitor) createTableWithWidth(width int) table.Model {
+func (pm *ProcessMonitor) createTableWithWidth(width int, metricTitle string) table.Model {
 	commandWidth := (width - symbolWidth - pidWidth - userWidth - metricWidth)
 	columns := []table.Column{
 		{Title: "   Key", Width: symbolWidth},
 		{Title: "         PID", Width: pidWidth},
 		{Title: "        User", Width: userWidth},
-		{Title: "         %", Width: metricWidth},
+		{Title: metricTitle, Width: metricWidth},
 		{Title: "Command", Width: commandWidth},
 	}
 
 	t := table.New(
 		table.WithColumns(columns),
 		table.WithFocused(false),
-		table.WithHeight(6), // 1 header + 5 rows
+		table.WithHeight(pm.rowCount+1), // 1 header + rows
 	)
+	t.SetStyles(processTableStyles(false))
+	return t
+}
 
+// processTableStyles returns the table styles; the cursor row is only
+// highlighted while it shows the selected process
+func processTableStyles(selected bool) table.Styles {
 	s := table.DefaultStyles()
 	s.Header = s.Header.
 		BorderStyle(lipgloss.NormalBorder()).
@@ -87,9 +132,10 @@ func (pm *ProcessMonitor) createTableWithWidth(width int) table.Model {
 		Padding(0).Margin(0)
 	s.Cell = s.Cell.
 		Padding(0).Margin(0)
-
-	t.SetStyles(s)
-	return t
+	if selected {
+		s.Selected = s.Selected.Reverse(true)
+	}
+	return s
 }
 
 func createSymbolColors(count int) []lipgloss.Style {
@@ -115,113 +161,184 @@ func createSymbolColors(count int) []lipgloss.Style {
 	return colors
 }
 
-func (pm *ProcessMonitor) Init() tea.Cmd {
-	return nil
-}
-
-func (pm *ProcessMonitor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-	var cmds []tea.Cmd
-	pm.cpuTable, cmd = pm.cpuTable.Update(msg)
-	cmds = append(cmds, cmd)
-	pm.memTable, cmd = pm.memTable.Update(msg)
-	cmds = append(cmds, cmd)
-	pm.gpuTable, cmd = pm.gpuTable.Update(msg)
-	cmds = append(cmds, cmd)
-	pm.gpuMemTable, cmd = pm.gpuMemTable.Update(msg)
-	cmds = append(cmds, cmd)
-	return pm, tea.Batch(cmds...)
+// Update refreshes the tables from CPU or GPU metrics, keeping the latest
+// process list of the other kind
+func (pm *ProcessMonitor) Update(msg interface{}) {
+	switch msg.(type) {
+	case domain.CPUMemoryMetrics, domain.GPUMetrics:
+		if pm.ownsRanking {
+			pm.ranking.Update(msg)
+		}
+		pm.refresh()
+	}
 }
 
 func (pm *ProcessMonitor) View() string {
 	padding := lipgloss.NewStyle().PaddingRight(2).Render
 
-	cpuView := pm.borderStyle.Render(lipgloss.JoinVertical(
-		lipgloss.Left,
-		"CPU %",
-		pm.cpuTable.View(),
-	))
-
-	memView := pm.borderStyle.Render(lipgloss.JoinVertical(
-		lipgloss.Left,
-		"MEM %",
-		pm.memTable.View(),
-	))
-
-	gpuView := pm.borderStyle.Render(lipgloss.JoinVertical(
-		lipgloss.Left,
-		"GPU %",
-		pm.gpuTable.View(),
-	))
-
-	gpuMemView := pm.borderStyle.Render(lipgloss.JoinVertical(
-		lipgloss.Left,
-		"GPU MEM",
-		pm.gpuMemTable.View(),
-	))
-
-	// Calculate minimum width needed for 2x2 layout
-	minTableWidth := symbolWidth + pidWidth + userWidth + metricWidth + minCommandWidth
-	paddingWidth := 6 // Account for borders and padding between tables
-	min2x2Width := 2*minTableWidth + paddingWidth
+	views := make([]string, len(pm.tables))
+	for i, kind := range pm.tables {
+		t, title := pm.table(kind)
+		if (kind == processIORead || kind == processIOWrite) && pm.ioHidden > 0 {
+			title += fmt.Sprintf("  (%d more unreadable)", pm.ioHidden)
+		}
+		views[i] = pm.borderStyle.Render(lipgloss.JoinVertical(
+			lipgloss.Left,
+			title,
+			t.View(),
+		))
+	}
 
 	// Use responsive layout based on available width
 	var view string
-	if pm.width >= min2x2Width {
-		// Wide screen: use 2x2 grid
-		topRow := lipgloss.JoinHorizontal(lipgloss.Top, padding(cpuView), memView)
-		bottomRow := lipgloss.JoinHorizontal(lipgloss.Top, padding(gpuView), gpuMemView)
-		view = lipgloss.JoinVertical(lipgloss.Left, topRow, bottomRow)
+	if pm.wide() {
+		// Wide screen: use a two-column grid
+		rows := make([]string, 0, (len(views)+1)/2)
+		for i := 0; i < len(views); i += 2 {
+			if i+1 < len(views) {
+				rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, padding(views[i]), views[i+1]))
+			} else {
+				rows = append(rows, views[i])
+			}
+		}
+		view = lipgloss.JoinVertical(lipgloss.Left, rows...)
 	} else {
 		// Narrow screen: stack vertically
-		view = lipgloss.JoinVertical(lipgloss.Left, cpuView, memView, gpuView, gpuMemView)
+		view = lipgloss.JoinVertical(lipgloss.Left, views...)
 	}
 
 	return lipgloss.NewStyle().Border(lipgloss.HiddenBorder()).Render(view)
 }
 
+// table returns the table for kind and its title
+func (pm *ProcessMonitor) table(kind processTable) (*table.Model, string) {
+	switch kind {
+	case processMem:
+		return &pm.memTable, "MEM %"
+	case processIORead:
+		return &pm.ioReadTable, "IO R"
+	case processIOWrite:
+		return &pm.ioWriteTable, "IO W"
+	case processGPU:
+		return &pm.gpuTable, "GPU %"
+	case processGPUMem:
+		return &pm.gpuMemTable, "GPU MEM"
+	default:
+		return &pm.cpuTable, "CPU %"
+	}
+}
+
+// wide reports whether two tables fit side by side
+func (pm *ProcessMonitor) wide() bool {
+	// Calculate minimum width needed for 2x2 layout
+	minTableWidth := symbolWidth + pidWidth + userWidth + metricWidth + minCommandWidth
+	paddingWidth := 6 // Account for borders and padding between tables
+	return pm.width >= 2*minTableWidth+paddingWidth
+}
+
+// UpdateProcesses ranks the given process lists and refreshes the tables
 func (pm *ProcessMonitor) UpdateProcesses(cpuProcesses []domain.CPUProcessInfo, gpuProcesses []domain.GPUProcessInfo) {
-	pm.cpuProcesses = cpuProcesses
-	pm.gpuProcesses = gpuProcesses
+	pm.ranking.setCPU(cpuProcesses)
+	pm.ranking.setGPU(gpuProcesses)
+	pm.refresh()
+}
+
+// refresh fills the tables from the ranking
+func (pm *ProcessMonitor) refresh() {
+	r := pm.ranking
+	cpuPercent := func(p domain.CPUProcessInfo) float64 { return p.CPUPercent }
+	pm.setRows(processCPU, pm.getRows(r.cpu, cpuPercent), cpuPIDs(r.cpu, pm.rowCount))
+	memoryPercent := func(p domain.CPUProcessInfo) float64 { return p.MemoryPercent }
+	pm.setRows(processMem, pm.getRows(r.memory, memoryPercent), cpuPIDs(r.memory, pm.rowCount))
+
+	pm.setRows(processIORead, pm.getIORows(r.ioRead, readRate), cpuPIDs(r.ioRead, pm.rowCount))
+	pm.setRows(processIOWrite, pm.getIORows(r.ioWrite, writeRate), cpuPIDs(r.ioWrite, pm.rowCount))
+	pm.ioHidden = hiddenUnknownIO(r.ioRead, pm.rowCount)
+
+	smUtil := func(p domain.GPUProcessInfo) float64 { return float64(p.SmUtil) }
+	pm.setRows(processGPU, pm.getGPURows(r.gpu, r.commands, smUtil), gpuPIDs(r.gpu, pm.rowCount))
+	gpuMemory := func(p domain.GPUProcessInfo) float64 { return p.UsedGpuMemory }
+	pm.setRows(processGPUMem, pm.getGPURows(r.gpuMemory, r.commands, gpuMemory), gpuPIDs(r.gpuMemory, pm.rowCount))
+}
 
-	// Create a map of PID to Command from CPU processes for GPU command lookup
-	pidToCommandForGPU := make(map[uint32]string)
-	for _, p := range pm.cpuProcesses {
-		pidToCommandForGPU[p.Pid] = p.Command
+// setRows fills a table, remembering which process each row shows and
+// moving the highlight to the selected process
+func (pm *ProcessMonitor) setRows(kind processTable, rows []table.Row, pids []uint32) {
+	t, _ := pm.table(kind)
+	t.SetRows(rows)
+	pm.rowPIDs[kind] = pids
+
+	cursor := -1
+	for i, pid := range pm.rowPIDs[kind] {
+		if pm.selectedPID != 0 && pid == pm.selectedPID {
+			cursor = i
+			break
+		}
 	}
+	t.SetCursor(max(cursor, 0))
+	t.SetStyles(processTableStyles(cursor >= 0))
+}
 
-	// Update CPU table - sort by CPU%
-	sort.Slice(pm.cpuProcesses, func(i, j int) bool {
-		return pm.cpuProcesses[i].CPUPercent > pm.cpuProcesses[j].CPUPercent
-	})
-	pm.cpuTable.SetRows(pm.getRows(pm.cpuProcesses, func(p domain.CPUProcessInfo) float64 { return p.CPUPercent }))
+func cpuPIDs(processes []domain.CPUProcessInfo, n int) []uint32 {
+	pids := make([]uint32, min(n, len(processes)))
+	for i := range pids {
+		pids[i] = processes[i].Pid
+	}
+	return pids
+}
 
-	// Update MEM table - sort by Memory%
-	sort.Slice(pm.cpuProcesses, func(i, j int) bool {
-		return pm.cpuProcesses[i].MemoryPercent > pm.cpuProcesses[j].MemoryPercent
-	})
-	pm.memTable.SetRows(pm.getRows(pm.cpuProcesses, func(p domain.CPUProcessInfo) float64 { return p.MemoryPercent }))
+func gpuPIDs(processes []domain.GPUProcessInfo, n int) []uint32 {
+	pids := make([]uint32, min(n, len(processes)))
+	for i := range pids {
+		pids[i] = processes[i].Pid
+	}
+	return pids
+}
 
-	// Update GPU table - sort by GPU%
-	sort.Slice(pm.gpuProcesses, func(i, j int) bool {
-		return pm.gpuProcesses[i].SmUtil > pm.gpuProcesses[j].SmUtil
-	})
-	pm.gpuTable.SetRows(pm.getGPURows(pm.gpuProcesses, pidToCommandForGPU, func(p domain.GPUProcessInfo) float64 { return float64(p.SmUtil) }))
+// SelectProcess highlights pid in every table showing it; 0 clears the
+// selection
+func (pm *ProcessMonitor) SelectProcess(pid uint32) {
+	pm.selectedPID = pid
+	pm.refresh()
+}
 
-	// Update GPU MEM table - sort by GPU Memory
-	sort.Slice(pm.gpuProcesses, func(i, j int) bool {
-		return pm.gpuProcesses[i].UsedGpuMemory > pm.gpuProcesses[j].UsedGpuMemory
-	})
-	pm.gpuMemTable.SetRows(pm.getGPURows(pm.gpuProcesses, pidToCommandForGPU, func(p domain.GPUProcessInfo) float64 { return p.UsedGpuMemory }))
+// ProcessAt returns the process on the table row at x, y relative to the
+// panel's top-left corner
+func (pm *ProcessMonitor) ProcessAt(x, y int) (uint32, bool) {
+	// The hidden border takes a line and a column on each side
+	x, y = x-1, y-1
+	blockHeight := tableChromeHeight + pm.rowCount
+	if x < 0 || y < 0 {
+		return 0, false
+	}
+
+	index := y / blockHeight
+	if pm.wide() {
+		index *= 2
+		// The left table is padded by two columns
+		if x >= pm.width/2-4+2 {
+			index++
+		}
+	}
+	if index >= len(pm.tables) {
+		return 0, false
+	}
+
+	row := y%blockHeight - tableChromeHeight
+	pids := pm.rowPIDs[pm.tables[index]]
+	if row < 0 || row >= len(pids) {
+		return 0, false
+	}
+	return pids[row], true
 }
 
 func (pm *ProcessMonitor) getRows(processes []domain.CPUProcessInfo, getValue func(domain.CPUProcessInfo) float64) []table.Row {
 	// Reuse the pre-allocated buffer
 	pm.rowBuffer = pm.rowBuffer[:0]
 	
-	for i := range min(5, len(processes)) {
+	for i := range min(pm.rowCount, len(processes)) {
 		p := processes[i]
-		sym, _ := pm.symbolAllocator.AccessPID(int(p.Pid))
+		sym, _ := pm.ranking.symbols.AccessPID(int(p.Pid))
 		
 		// Username is now pre-populated by the collector
 		user := p.User
@@ -240,13 +357,58 @@ func (pm *ProcessMonitor) getRows(processes []domain.CPUProcessInfo, getValue fu
 	return pm.rowBuffer
 }
 
+func sortByIO(processes []domain.CPUProcessInfo, getValue func(domain.CPUProcessInfo) float64) {
+	sort.Slice(processes, func(i, j int) bool {
+		if processes[i].IOKnown != processes[j].IOKnown {
+			return processes[i].IOKnown
+		}
+		return getValue(processes[i]) > getValue(processes[j])
+	})
+}
+
+// hiddenUnknownIO counts the processes with unknown I/O that sortByIO put
+// below the first n rows
+func hiddenUnknownIO(processes []domain.CPUProcessInfo, n int) int {
+	hidden := 0
+	for _, p := range processes[min(n, len(processes)):] {
+		if !p.IOKnown {
+			hidden++
+		}
+	}
+	return hidden
+}
+
+func (pm *ProcessMonitor) getIORows(processes []domain.CPUProcessInfo, getValue func(domain.CPUProcessInfo) float64) []table.Row {
+	// Reuse the pre-allocated buffer
+	pm.rowBuffer = pm.rowBuffer[:0]
+
+	for i := range min(pm.rowCount, len(processes)) {
+		p := processes[i]
+		sym, _ := pm.ranking.symbols.AccessPID(int(p.Pid))
+
+		rate := fmt.Sprintf("%10s", "?")
+		if p.IOKnown {
+			rate = fmt.Sprintf("%10s", formatBytes(uint64(getValue(p))))
+		}
+
+		pm.rowBuffer = append(pm.rowBuffer, table.Row{
+			pm.formatSymbol(sym),
+			pm.formatPID(p.Pid),
+			pm.formatUser(p.User),
+			rate,
+			p.Command,
+		})
+	}
+	return pm.rowBuffer
+}
+
 func (pm *ProcessMonitor) getGPURows(processes []domain.GPUProcessInfo, pidToCommand map[uint32]string, getValue func(domain.GPUProcessInfo) float64) []table.Row {
 	// Reuse the pre-allocated buffer
 	pm.rowBuffer = pm.rowBuffer[:0]
 	
-	for i := range min(5, len(processes)) {
+	for i := range min(pm.rowCount, len(processes)) {
 		p := processes[i]
-		sym, _ := pm.symbolAllocator.AccessPID(int(p.Pid))
+		sym, _ := pm.ranking.symbols.AccessPID(int(p.Pid))
 		command := pidToCommand[p.Pid]
 		
 		pm.rowBuffer = append(pm.rowBuffer, table.Row{
@@ -333,29 +495,39 @@ func min(a, b int) int {
 	return b
 }
 
-func (pm *ProcessMonitor) Resize(width int) {
+// Resize fits the tables to width and, when height is non-zero, shows as
+// many processes per table as fit
+func (pm *ProcessMonitor) Resize(width, height int) {
 	pm.width = width
-	
-	// Calculate minimum width needed for 2x2 layout
-	minTableWidth := symbolWidth + pidWidth + userWidth + metricWidth + minCommandWidth
-	paddingWidth := 6 // Account for borders and padding between tables
-	min2x2Width := 2*minTableWidth + paddingWidth
 
 	var tableWidth int
-	if width >= min2x2Width {
+	gridRows := len(pm.tables)
+	if pm.wide() {
 		// Wide screen: use half width for 2x2 grid
 		tableWidth = width/2 - 4
+		gridRows = (gridRows + 1) / 2
 	} else {
 		// Narrow screen: use full width for vertical stack
 		tableWidth = width - 4
 	}
 
-	updateTable := func(t *table.Model) {
-		*t = pm.createTableWithWidth(tableWidth)
+	pm.rowCount = defaultProcessRows
+	if height > 0 && gridRows > 0 {
+		// The hidden border adds a line above and below
+		pm.rowCount = max((height-2)/gridRows-tableChromeHeight, 1)
 	}
 
-	updateTable(&pm.cpuTable)
-	updateTable(&pm.memTable)
-	updateTable(&pm.gpuTable)
-	updateTable(&pm.gpuMemTable)
+	updateTable := func(t *table.Model, metricTitle string) {
+		*t = pm.createTableWithWidth(tableWidth, metricTitle)
+	}
+
+	updateTable(&pm.cpuTable, percentTitle)
+	updateTable(&pm.memTable, percentTitle)
+	updateTable(&pm.ioReadTable, rateTitle)
+	updateTable(&pm.ioWriteTable, rateTitle)
+	updateTable(&pm.gpuTable, percentTitle)
+	updateTable(&pm.gpuMemTable, percentTitle)
+
+	// Refill the new tables so a resize does not blank them until the next tick
+	pm.refresh()
 }
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortByIO(t *testing.T) {
+	readRate := func(p domain.CPUProcessInfo) float64 { return p.ReadBytesPerSec }
+	tests := []struct {
+		name       string
+		processes  []domain.CPUProcessInfo
+		wantOrder  []uint32
+		wantHidden int
+	}{
+		{
+			name: "busiest first, unknown last",
+			processes: []domain.CPUProcessInfo{
+				{Pid: 1, IOKnown: true, ReadBytesPerSec: 10},
+				{Pid: 2, IOKnown: false, ReadBytesPerSec: 0},
+				{Pid: 3, IOKnown: true, ReadBytesPerSec: 300},
+				{Pid: 4, IOKnown: true, ReadBytesPerSec: 0},
+			},
+			wantOrder:  []uint32{3, 1, 4, 2},
+			wantHidden: 1,
+		},
+		{
+			name: "unknown below the rows are counted",
+			processes: []domain.CPUProcessInfo{
+				{Pid: 1, IOKnown: false},
+				{Pid: 2, IOKnown: true, ReadBytesPerSec: 5},
+				{Pid: 3, IOKnown: false},
+				{Pid: 4, IOKnown: true, ReadBytesPerSec: 50},
+				{Pid: 5, IOKnown: true},
+			},
+			wantOrder:  []uint32{4, 2, 5, 1, 3},
+			wantHidden: 2,
+		},
+		{
+			name: "unknown within the rows are shown",
+			processes: []domain.CPUProcessInfo{
+				{Pid: 1, IOKnown: false},
+				{Pid: 2, IOKnown: true, ReadBytesPerSec: 5},
+			},
+			wantOrder: []uint32{2, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortByIO(tt.processes, readRate)
+
+			assert.Equal(t, tt.wantOrder, cpuPIDs(tt.processes, len(tt.processes)))
+			assert.Equal(t, tt.wantHidden, hiddenUnknownIO(tt.processes, 3))
+		})
+	}
+}
+
+func TestProcessMonitorShowsHiddenUnknownIO(t *testing.T) {
+	pm := NewProcessMonitor(200)
+	processes := []domain.CPUProcessInfo{{Pid: 1, IOKnown: false, Command: "secret"}}
+	for pid := uint32(2); pid < 8; pid++ {
+		processes = append(processes, domain.CPUProcessInfo{Pid: pid, IOKnown: true, ReadBytesPerSec: 1})
+	}
+
+	pm.UpdateProcesses(processes, nil)
+
+	assert.Contains(t, pm.View(), "IO R  (1 more unreadable)")
+}
+
+func TestProcessTablesShareRanking(t *testing.T) {
+	ranking := newProcessRanking()
+	tables := newProcessTables(ranking)
+	processes := []domain.CPUProcessInfo{
+		{Pid: 10, CPUPercent: 1, MemoryPercent: 50},
+		{Pid: 20, CPUPercent: 90, MemoryPercent: 1},
+	}
+
+	ranking.Update(domain.CPUMemoryMetrics{Processes: processes})
+	for _, pm := range tables {
+		pm.Update(domain.CPUMemoryMetrics{Processes: processes})
+	}
+
+	// Sorted copies: the sample keeps its order
+	assert.Equal(t, uint32(10), processes[0].Pid)
+	assert.Equal(t, []uint32{20, 10}, tables[PanelCPUProcesses].rowPIDs[processCPU])
+	assert.Equal(t, []uint32{10, 20}, tables[PanelMemoryProcesses].rowPIDs[processMem])
+	// One allocator, so a process has the same symbol in every table
+	for _, pm := range tables {
+		assert.Same(t, ranking.symbols, pm.ranking.symbols)
+	}
+}
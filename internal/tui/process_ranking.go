@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"sort"
+
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+// processRanking holds the latest process lists sorted once per sample for
+// every process table, and gives each process one symbol in all of them.
+// The lists are copies, so sorting never touches the collector's sample.
+type processRanking struct {
+	cpu       []domain.CPUProcessInfo
+	memory    []domain.CPUProcessInfo
+	ioRead    []domain.CPUProcessInfo
+	ioWrite   []domain.CPUProcessInfo
+	gpu       []domain.GPUProcessInfo
+	gpuMemory []domain.GPUProcessInfo
+	commands  map[uint32]string // command of each CPU process, for the GPU tables
+	symbols   *SymbolAllocator
+}
+
+func newProcessRanking() *processRanking {
+	return &processRanking{
+		commands: make(map[uint32]string),
+		symbols:  NewSymbolAllocator([]rune{'▣', '▤', '▥', '▦', '▧', '▨', '▩', '▪', '▫', '▬', '◆', '◇', '○', '●', '◉', '◍', '◎', '◌', '◔', '◕'}),
+	}
+}
+
+// Update re-sorts the lists of CPU or GPU metrics, keeping the latest list
+// of the other kind
+func (r *processRanking) Update(msg any) {
+	switch msg := msg.(type) {
+	case domain.CPUMemoryMetrics:
+		r.setCPU(msg.Processes)
+	case domain.GPUMetrics:
+		r.setGPU(msg.Processes)
+	}
+}
+
+func (r *processRanking) setCPU(processes []domain.CPUProcessInfo) {
+	r.cpu = append(r.cpu[:0], processes...)
+	sort.Slice(r.cpu, func(i, j int) bool { return r.cpu[i].CPUPercent > r.cpu[j].CPUPercent })
+
+	r.memory = append(r.memory[:0], processes...)
+	sort.Slice(r.memory, func(i, j int) bool { return r.memory[i].MemoryPercent > r.memory[j].MemoryPercent })
+
+	// Processes with unknown I/O last
+	r.ioRead = append(r.ioRead[:0], processes...)
+	sortByIO(r.ioRead, readRate)
+	r.ioWrite = append(r.ioWrite[:0], processes...)
+	sortByIO(r.ioWrite, writeRate)
+
+	clear(r.commands)
+	for _, p := range processes {
+		r.commands[p.Pid] = p.Command
+	}
+}
+
+func (r *processRanking) setGPU(processes []domain.GPUProcessInfo) {
+	r.gpu = append(r.gpu[:0], processes...)
+	sort.Slice(r.gpu, func(i, j int) bool { return r.gpu[i].SmUtil > r.gpu[j].SmUtil })
+
+	r.gpuMemory = append(r.gpuMemory[:0], processes...)
+	sort.Slice(r.gpuMemory, func(i, j int) bool { return r.gpuMemory[i].UsedGpuMemory > r.gpuMemory[j].UsedGpuMemory })
+}
+
+func readRate(p domain.CPUProcessInfo) float64  { return p.ReadBytesPerSec }
+func writeRate(p domain.CPUProcessInfo) float64 { return p.WriteBytesPerSec }
@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NimbleMarkets/ntcharts/sparkline"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+var psiLabelStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("4")) // blue
+
+var psiUnavailableStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("8")) // gray
+
+// psiRow tracks the "some" avg10 history for one resource
+type psiRow struct {
+	label string
+	chart *sparkline.Model
+	value func(domain.PressureSet) domain.ResourcePressure
+}
+
+// PSIPanel renders Linux pressure stall information as a compact block of
+// sparklines, one per resource, for the system and optionally a cgroup.
+type PSIPanel struct {
+	focusState
+	metrics    domain.PSIMetrics
+	received   bool
+	systemRows []*psiRow
+	cgroupRows []*psiRow
+	width      int
+}
+
+func NewPSIPanel() *PSIPanel {
+	p := &PSIPanel{width: 80}
+	p.systemRows = p.newRows()
+	p.cgroupRows = p.newRows()
+	return p
+}
+
+func (p *PSIPanel) newRows() []*psiRow {
+	rows := []*psiRow{
+		{label: "cpu", value: func(s domain.PressureSet) domain.ResourcePressure { return s.CPU }},
+		{label: "mem", value: func(s domain.PressureSet) domain.ResourcePressure { return s.Memory }},
+		{label: "io", value: func(s domain.PressureSet) domain.ResourcePressure { return s.IO }},
+	}
+	for _, row := range rows {
+		chart := sparkline.New(p.chartWidth(), 1, sparkline.WithMaxValue(100))
+		row.chart = &chart
+	}
+	return rows
+}
+
+func (p *PSIPanel) Update(msg interface{}) {
+	if p == nil {
+		return
+	}
+	switch msg := msg.(type) {
+	case domain.PSIMetrics:
+		p.metrics = msg
+		p.received = true
+		if msg.Available {
+			p.pushRows(p.systemRows, msg.System)
+		}
+		if msg.CgroupAvailable {
+			p.pushRows(p.cgroupRows, msg.CgroupPressure)
+		}
+	}
+}
+
+func (p *PSIPanel) pushRows(rows []*psiRow, set domain.PressureSet) {
+	for _, row := range rows {
+		row.chart.Push(row.value(set).Some.Avg10)
+	}
+}
+
+func (p *PSIPanel) View() string {
+	if p == nil || !p.received {
+		return ""
+	}
+	if !p.metrics.Available {
+		return psiUnavailableStyle.Render("    Pressure: PSI not available (kernel built without CONFIG_PSI or psi=0)")
+	}
+
+	lines := []string{"    Pressure (some avg10/avg60/avg300, full avg10)"}
+	lines = append(lines, p.renderRows("system", p.systemRows, p.metrics.System)...)
+
+	if p.metrics.Cgroup != "" {
+		if p.metrics.CgroupAvailable {
+			lines = append(lines, p.renderRows(p.metrics.Cgroup, p.cgroupRows, p.metrics.CgroupPressure)...)
+		} else {
+			lines = append(lines, psiUnavailableStyle.Render(
+				fmt.Sprintf("    %s: cgroup pressure not available", p.metrics.Cgroup)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderRows renders the rows of one scope with the readings in set; scope
+// is only the label, so a cgroup named "system" is still shown correctly
+func (p *PSIPanel) renderRows(scope string, rows []*psiRow, set domain.PressureSet) []string {
+	var lines []string
+	for _, row := range rows {
+		pressure := row.value(set)
+		row.chart.DrawBraille()
+		lines = append(lines, fmt.Sprintf("    %s %s %6.2f %6.2f %6.2f  full %6.2f",
+			psiLabelStyle.Render(fmt.Sprintf("%-12.12s %-3s", scope, row.label)),
+			pressureStyle(pressure.Some.Avg10).Render(row.chart.View()),
+			pressure.Some.Avg10,
+			pressure.Some.Avg60,
+			pressure.Some.Avg300,
+			pressure.Full.Avg10,
+		))
+	}
+	return lines
+}
+
+// pressureStyle colors a stall percentage like the busiest cores view
+func pressureStyle(avg float64) lipgloss.Style {
+	switch {
+	case avg > 40:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	case avg > 10:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	default:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	}
+}
+
+func (p *PSIPanel) chartWidth() int {
+	// label + numbers take roughly 60 columns
+	return max(p.width-64, 5)
+}
+
+func (p *PSIPanel) Resize(width, height int) {
+	if p == nil {
+		return
+	}
+	p.width = width
+	for _, row := range append(p.systemRows, p.cgroupRows...) {
+		row.chart.Resize(p.chartWidth(), 1)
+	}
+}
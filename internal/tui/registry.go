@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Source is a running collector as seen by the model
+type Source interface {
+	Start()
+	Stop()
+	listen(name string) tea.Cmd
+}
+
+// metricsMsg carries a value from the named source
+type metricsMsg struct {
+	source string
+	value  any
+}
+
+type collectorSource[T any] struct {
+	collector metricsCollector[T]
+}
+
+// NewSource adapts a metrics collector to a Source
+func NewSource[T any](collector metricsCollector[T]) Source {
+	return collectorSource[T]{collector: collector}
+}
+
+func (s collectorSource[T]) Start() { s.collector.Start() }
+func (s collectorSource[T]) Stop()  { s.collector.Stop() }
+
+func (s collectorSource[T]) listen(name string) tea.Cmd {
+	metrics := s.collector.Metrics()
+	return func() tea.Msg {
+		value, ok := <-metrics
+		if !ok {
+			return nil
+		}
+		return metricsMsg{source: name, value: value}
+	}
+}
+
+// Stream starts a source and returns its values, for consumers other than
+// the model such as the agent. The channel closes when the source stops.
+func Stream(source Source) <-chan any {
+	values := make(chan any)
+	source.Start()
+	go func() {
+		defer close(values)
+		listen := source.listen("")
+		for {
+			msg, ok := listen().(metricsMsg)
+			if !ok {
+				return
+			}
+			values <- msg.value
+		}
+	}()
+	return values
+}
+
+// Plugin declares a collector, how to detect whether it can run, and the
+// panel that renders its metrics
+type Plugin struct {
+	Name        string
+	Description string
+	// Available probes whether the collector can run on this machine; nil
+	// means always available
+	Available func() bool
+	// DefaultEnabled plugins run unless disabled by flag or config
+	DefaultEnabled bool
+	// NewSource constructs the collector without starting it
+	NewSource func() Source
+	// NewPanel constructs the plugin's panel, which layouts refer to by the
+	// plugin name; nil for collectors that only feed the built-in views
+	NewPanel func() Panel
+}
+
+// IsAvailable runs the plugin's availability probe
+func (p Plugin) IsAvailable() bool {
+	return p.Available == nil || p.Available()
+}
+
+// Registry holds the plugins known to the application, in registration order
+type Registry struct {
+	plugins []Plugin
+	byName  map[string]int
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		byName: make(map[string]int),
+	}
+}
+
+// Register adds a plugin; names must be unique
+func (r *Registry) Register(plugin Plugin) error {
+	if plugin.Name == "" {
+		return fmt.Errorf("plugin has no name")
+	}
+	if plugin.NewSource == nil {
+		return fmt.Errorf("plugin %q has no collector", plugin.Name)
+	}
+	if _, exists := r.byName[plugin.Name]; exists {
+		return fmt.Errorf("plugin %q already registered", plugin.Name)
+	}
+	r.byName[plugin.Name] = len(r.plugins)
+	r.plugins = append(r.plugins, plugin)
+	return nil
+}
+
+// Plugins returns all registered plugins in registration order
+func (r *Registry) Plugins() []Plugin {
+	return append([]Plugin(nil), r.plugins...)
+}
+
+// Resolve selects the plugins to run. enabled overrides DefaultEnabled per
+// plugin name; naming an unknown plugin, or enabling one that is unavailable
+// on this machine, is an error. Unavailable plugins enabled by default are
+// skipped.
+func (r *Registry) Resolve(enabled map[string]bool) ([]Plugin, error) {
+	var unknown []string
+	for name := range enabled {
+		if _, exists := r.byName[name]; !exists {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown collectors: %v", unknown)
+	}
+
+	var selected []Plugin
+	var unavailable []string
+	for _, plugin := range r.plugins {
+		on, overridden := enabled[plugin.Name]
+		if !overridden {
+			on = plugin.DefaultEnabled
+		}
+		switch {
+		case !on:
+		case plugin.IsAvailable():
+			selected = append(selected, plugin)
+		case overridden:
+			unavailable = append(unavailable, plugin.Name)
+		}
+	}
+	if len(unavailable) > 0 {
+		return nil, fmt.Errorf("collectors not available on this machine: %v", unavailable)
+	}
+	return selected, nil
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+// statsProcesses is how many processes the statistics list
+const statsProcesses = 10
+
+// FormatRunStats renders run statistics as plain text tables: one row per
+// series, totals and GPUs before the cores, then the n busiest processes
+func FormatRunStats(stats *domain.RunStats, n int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-14s %8s %8s %8s %8s %8s\n", "series", "samples", "min", "avg", "p95", "max")
+	var cores []string
+	for _, name := range stats.Series() {
+		if isCoreSeries(name) {
+			cores = append(cores, name)
+			continue
+		}
+		writeSeries(&b, name, stats.Get(name))
+	}
+	for _, name := range cores {
+		writeSeries(&b, name, stats.Get(name))
+	}
+
+	processes := stats.Processes(n)
+	if len(processes) == 0 {
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\n%-8s %-10s %-20s %8s %8s %8s %8s %8s %8s %8s\n",
+		"pid", "user", "command", "cpu avg", "cpu p95", "cpu max", "mem avg", "mem max", "gpu avg", "gpu max")
+	for _, p := range processes {
+		fmt.Fprintf(&b, "%-8d %-10s %-20s %s %s %s %s %s %s %s\n",
+			p.Pid, truncate(p.User, 10), truncate(p.Command, 20),
+			statValue(p.CPU, p.CPU.Mean), statValue(p.CPU, func() float64 { return p.CPU.Percentile(95) }), statValue(p.CPU, p.CPU.Max),
+			statValue(p.Memory, p.Memory.Mean), statValue(p.Memory, p.Memory.Max),
+			statValue(p.GPU, p.GPU.Mean), statValue(p.GPU, p.GPU.Max))
+	}
+	return b.String()
+}
+
+func writeSeries(b *strings.Builder, name string, s *domain.Stats) {
+	fmt.Fprintf(b, "%-14s %8d %7.1f%% %7.1f%% %7.1f%% %7.1f%%\n", name, s.Count(), s.Min(), s.Mean(), s.Percentile(95), s.Max())
+}
+
+// statValue formats a percentage, or a dash for a series with no samples
+func statValue(s *domain.Stats, value func() float64) string {
+	if s.Count() == 0 {
+		return fmt.Sprintf("%8s", "-")
+	}
+	return fmt.Sprintf("%7.1f%%", value())
+}
+
+// isCoreSeries matches the per-core series, cpu0, cpu1 and so on
+func isCoreSeries(name string) bool {
+	rest, ok := strings.CutPrefix(name, "cpu")
+	return ok && rest != "" && unicode.IsDigit(rune(rest[0]))
+}
+
+// statsView shows the statistics of the session so far in place of the
+// panels; it scrolls like them
+func (m Model) statsView() string {
+	if m.stats == nil || len(m.stats.Series()) == 0 {
+		return "No samples yet"
+	}
+	return "Statistics since start\n\n" + FormatRunStats(m.stats, statsProcesses)
+}
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+// SystemActivityHeader renders a one-line summary of load averages,
+// run queue and kernel event rates
+type SystemActivityHeader struct {
+	focusState
+	metrics    domain.SystemActivityMetrics
+	received   bool
+	cores      int // taken from CPU metrics, used to color load averages
+	calculator *domain.ActivityCalculator
+	// Cached styles
+	labelStyle  lipgloss.Style
+	lowStyle    lipgloss.Style // load below 70% of cores
+	mediumStyle lipgloss.Style // load between 70% and 100% of cores
+	highStyle   lipgloss.Style // load above core count
+}
+
+func NewSystemActivityHeader() *SystemActivityHeader {
+	return &SystemActivityHeader{
+		calculator:  domain.NewActivityCalculator(),
+		labelStyle:  lipgloss.NewStyle().Foreground(lipgloss.Color("4")),
+		lowStyle:    lipgloss.NewStyle().Foreground(lipgloss.Color("6")), // Cyan
+		mediumStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("3")), // Yellow
+		highStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color("1")), // Red
+	}
+}
+
+func (h *SystemActivityHeader) Update(msg interface{}) {
+	if h == nil {
+		return
+	}
+	switch msg := msg.(type) {
+	case domain.SystemActivityMetrics:
+		h.metrics = msg
+		h.received = true
+	case domain.CPUMemoryMetrics:
+		h.cores = len(msg.CPUUsagePerCore)
+	}
+}
+
+func (h *SystemActivityHeader) View() string {
+	if h == nil || !h.received {
+		return ""
+	}
+	return fmt.Sprintf("    %s %s %s %s   %s %d/%d   %s %s   %s %s   %s %s",
+		h.labelStyle.Render("Load:"),
+		h.renderLoad(h.metrics.Load1),
+		h.renderLoad(h.metrics.Load5),
+		h.renderLoad(h.metrics.Load15),
+		h.labelStyle.Render("Run/Blk:"),
+		h.metrics.ProcsRunning,
+		h.metrics.ProcsBlocked,
+		h.labelStyle.Render("Ctxt/s:"),
+		formatCount(h.metrics.ContextSwitchesPerSec),
+		h.labelStyle.Render("Intr/s:"),
+		formatCount(h.metrics.InterruptsPerSec),
+		h.labelStyle.Render("Forks/s:"),
+		formatCount(h.metrics.ForksPerSec),
+	)
+}
+
+func (h *SystemActivityHeader) renderLoad(load float64) string {
+	if h.cores == 0 {
+		// Without a core count the load cannot be judged, so leave it plain
+		return fmt.Sprintf("%.2f", load)
+	}
+	ratio := h.calculator.LoadRatio(load, h.cores)
+	style := h.lowStyle
+	if ratio > 1.0 {
+		style = h.highStyle
+	} else if ratio > 0.7 {
+		style = h.mediumStyle
+	}
+	return style.Render(fmt.Sprintf("%.2f", load))
+}
+
+func (h *SystemActivityHeader) Resize(width, height int) {}
+
+// formatCount renders a rate with a k/M suffix
+func formatCount(v float64) string {
+	switch {
+	case v >= 1e6:
+		return fmt.Sprintf("%.1fM", v/1e6)
+	case v >= 1e3:
+		return fmt.Sprintf("%.1fk", v/1e3)
+	default:
+		return fmt.Sprintf("%.0f", v)
+	}
+}
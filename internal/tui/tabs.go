@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	tabStyle       = lipgloss.NewStyle().Padding(0, 1).Foreground(lipgloss.Color("8"))
+	activeTabStyle = lipgloss.NewStyle().Padding(0, 1).Reverse(true)
+)
+
+const tabBarHeight = 1
+
+// tab is one screen of the TUI. The overview has no layout of its own and
+// shows the configured layout.
+type tab struct {
+	name   string
+	layout Layout
+}
+
+// defaultTabs returns the screens selectable with the number keys. Tabs
+// other than the overview use weighted rows so they fill the terminal.
+func defaultTabs() []tab {
+	return []tab{
+		{name: "Overview"},
+		{name: "CPU", layout: Layout{Rows: []LayoutRow{
+			{Panels: []LayoutPanel{{Name: "activity"}}},
+			{Weight: 1, Panels: []LayoutPanel{{Name: PanelCPUGraph}}},
+			{Weight: 1, Panels: []LayoutPanel{{Name: PanelCPUCores}}},
+			{Panels: []LayoutPanel{{Name: PanelCPUProcesses}}},
+		}}},
+		{name: "Memory", layout: Layout{Rows: []LayoutRow{
+			{Weight: 2, Panels: []LayoutPanel{{Name: PanelMemory}}},
+			{Panels: []LayoutPanel{{Name: "psi"}}},
+			{Weight: 1, Panels: []LayoutPanel{{Name: PanelMemoryProcesses}}},
+		}}},
+		{name: "GPU", layout: Layout{Rows: []LayoutRow{
+			{Weight: 1, Panels: []LayoutPanel{{Name: PanelCPUGraph}, {Name: PanelMemory}}},
+			{Weight: 1, Panels: []LayoutPanel{{Name: PanelGPUProcesses}}},
+		}}},
+		{name: "Processes", layout: Layout{Rows: []LayoutRow{
+			{Panels: []LayoutPanel{{Name: PanelWatched}}},
+			{Weight: 1, Panels: []LayoutPanel{{Name: PanelProcesses}}},
+		}}},
+		{name: "Disk/Net", layout: Layout{Rows: []LayoutRow{
+			{Weight: 1, Panels: []LayoutPanel{{Name: "disknet"}}},
+			{Panels: []LayoutPanel{{Name: "psi"}}},
+			{Weight: 1, Panels: []LayoutPanel{{Name: PanelIOProcesses}}},
+		}}},
+	}
+}
+
+// tabBarView renders the screen names followed by the monitored host, when
+// it is remote
+func tabBarView(tabs []tab, active int, host string) string {
+	names := make([]string, len(tabs))
+	for i, t := range tabs {
+		style := tabStyle
+		if i == active {
+			style = activeTabStyle
+		}
+		names[i] = style.Render(string(rune('1'+i)) + " " + t.name)
+	}
+	if host != "" {
+		names = append(names, labelStyle.Render("  "+host))
+	}
+	return strings.Join(names, "")
+}
@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NimbleMarkets/ntcharts/sparkline"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jonsampson/mim/internal/domain"
+)
+
+var watchHeaderStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("4")) // blue
+
+var watchExitedStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("8")) // gray
+
+// watchCharts is the history of one watched process
+type watchCharts struct {
+	cpu    *sparkline.Model
+	memory *sparkline.Model
+	gpu    *sparkline.Model
+}
+
+// WatchPanel lists the watched processes whatever their rank, with a
+// sparkline each of their CPU, memory and GPU use. Exited processes keep
+// their row until the watch list drops them.
+type WatchPanel struct {
+	focusState
+	watch  *domain.WatchList
+	charts map[uint32]*watchCharts
+	gpu    bool // a GPU sample was seen, so the GPU columns are shown
+	width  int
+	height int
+}
+
+func NewWatchPanel(watch *domain.WatchList) *WatchPanel {
+	return &WatchPanel{watch: watch, charts: make(map[uint32]*watchCharts), width: 80}
+}
+
+func (p *WatchPanel) Update(msg interface{}) {
+	p.watch.Update(msg)
+	// Every row takes a sample, so the histories stay aligned in time
+	switch msg.(type) {
+	case domain.CPUMemoryMetrics:
+		for _, watched := range p.watch.Processes() {
+			charts := p.chartsOf(watched.Pid)
+			charts.cpu.Push(watched.CPUPercent)
+			charts.memory.Push(watched.MemoryPercent)
+		}
+		p.dropCharts()
+	case domain.GPUMetrics:
+		if !p.gpu {
+			// A third chart column appears
+			p.gpu = true
+			p.Resize(p.width, p.height)
+		}
+		for _, watched := range p.watch.Processes() {
+			p.chartsOf(watched.Pid).gpu.Push(watched.GPUPercent)
+		}
+	}
+}
+
+func (p *WatchPanel) chartsOf(pid uint32) *watchCharts {
+	charts, ok := p.charts[pid]
+	if !ok {
+		width := p.chartWidth()
+		charts = &watchCharts{cpu: newPercentSparkline(width), memory: newPercentSparkline(width), gpu: newPercentSparkline(width)}
+		p.charts[pid] = charts
+	}
+	return charts
+}
+
+// dropCharts forgets the histories of processes no longer watched
+func (p *WatchPanel) dropCharts() {
+	if len(p.charts) <= len(p.watch.Processes()) {
+		return
+	}
+	watched := make(map[uint32]bool, len(p.watch.Processes()))
+	for _, process := range p.watch.Processes() {
+		watched[process.Pid] = true
+	}
+	for pid := range p.charts {
+		if !watched[pid] {
+			delete(p.charts, pid)
+		}
+	}
+}
+
+func (p *WatchPanel) View() string {
+	width := p.chartWidth()
+	header := fmt.Sprintf("    %-9s %7s  %-8s %6s %-*s %6s %-*s", "Watched", "PID", "USER", "CPU%", width, "", "MEM%", width, "")
+	if p.gpu {
+		header += fmt.Sprintf(" %6s %-*s", "GPU%", width, "")
+	}
+	lines := []string{watchHeaderStyle.Render(header + "  COMMAND")}
+
+	if len(p.watch.Processes()) == 0 {
+		lines = append(lines, watchExitedStyle.Render("    no matching processes yet"))
+	}
+	for _, watched := range p.watch.Processes() {
+		charts := p.chartsOf(watched.Pid)
+		state := "running"
+		switch {
+		case watched.Exited:
+			state = "exited"
+		case !watched.Seen:
+			state = "not found"
+		}
+		line := fmt.Sprintf("    %-9s %7d  %-8.8s %5.1f%% %s %5.1f%% %s",
+			state, watched.Pid, watched.User,
+			watched.CPUPercent, drawSparkline(charts.cpu),
+			watched.MemoryPercent, drawSparkline(charts.memory))
+		if p.gpu {
+			line += fmt.Sprintf(" %5.1f%% %s", watched.GPUPercent, drawSparkline(charts.gpu))
+		}
+		line += "  " + watched.Command
+		if watched.Exited {
+			line = watchExitedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	if p.height > 0 && len(lines) > p.height {
+		lines = lines[:p.height]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func drawSparkline(chart *sparkline.Model) string {
+	chart.Draw()
+	return chart.View()
+}
+
+func (p *WatchPanel) chartWidth() int {
+	charts := 2
+	if p.gpu {
+		charts = 3
+	}
+	// State, PID and user take 31 columns, each value 8 and the command at
+	// least 18
+	return min(max((p.width-31-18-8*charts)/charts, 5), 30)
+}
+
+func (p *WatchPanel) Resize(width, height int) {
+	p.width = width
+	p.height = height
+	for _, charts := range p.charts {
+		for _, chart := range []*sparkline.Model{charts.cpu, charts.memory, charts.gpu} {
+			chart.Resize(p.chartWidth(), 1)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package tui
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/jonsampson/mim/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchPanelDropsCharts(t *testing.T) {
+	panel := NewWatchPanel(domain.NewWatchList(nil, regexp.MustCompile(`^job`)))
+	for pid := range uint32(50) {
+		panel.Update(domain.CPUMemoryMetrics{Processes: []domain.CPUProcessInfo{{Pid: pid, Command: "job"}}})
+	}
+
+	assert.Len(t, panel.charts, len(panel.watch.Processes()))
+	assert.Contains(t, panel.charts, uint32(49))
+	assert.NotContains(t, panel.charts, uint32(0))
+}